@@ -50,14 +50,28 @@ func main() {
 	}
 	defer pg.Pool.Close()
 
-	rd := queue.NewRedis(cfg)
-	if err := rd.Ping(ctx); err != nil {
-		logger.Error("failed to connect redis", "err", err)
-		os.Exit(1)
+	// In Postgres-only mode the queue stays a nil interface so the server
+	// skips publishing and readiness ignores Redis.
+	var jobQueue api.Queue
+	if cfg.UsesRedis() {
+		rd := queue.NewRedis(cfg)
+		if err := rd.Ping(ctx); err != nil {
+			logger.Error("failed to connect redis", "err", err)
+			os.Exit(1)
+		}
+		jobQueue = rd
 	}
 
 	store := api.NewPostgresStore(pg.Pool)
-	srv := api.NewServer(cfg, store, rd, nil, logger)
+	srv := api.NewServer(cfg, store, jobQueue, nil, logger)
+
+	// One LISTEN connection feeds every GET /jobs/{id}/wait waiter and
+	// GET /events stream.
+	feedCtx, stopFeed := context.WithCancel(ctx)
+	defer stopFeed()
+	hub := api.NewEventHub()
+	srv.SetEventHub(hub)
+	go storage.NewJobEventFeed(pg.Pool).Run(feedCtx, hub)
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
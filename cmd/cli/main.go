@@ -13,11 +13,24 @@ import (
 )
 
 type submitJobRequest struct {
+	Queue          string          `json:"queue,omitempty"`
 	JobType        string          `json:"jobType"`
 	Payload        json.RawMessage `json:"payload"`
 	IdempotencyKey string          `json:"idempotencyKey"`
 	MaxRetries     int             `json:"maxRetries,omitempty"`
 	MaxAttempts    int             `json:"maxAttempts,omitempty"`
+	Priority       int             `json:"priority,omitempty"`
+	RunAt          string          `json:"runAt,omitempty"`
+	Delay          string          `json:"delay,omitempty"`
+	Timeout        string          `json:"timeout,omitempty"`
+}
+
+// waitResponse is the subset of GET /jobs/{id}/wait the CLI inspects.
+type waitResponse struct {
+	Done bool `json:"done"`
+	Job  struct {
+		State string `json:"state"`
+	} `json:"job"`
 }
 
 func main() {
@@ -31,6 +44,10 @@ func main() {
 		cmdEnqueue(os.Args[2:])
 	case "status":
 		cmdStatus(os.Args[2:])
+	case "attempts":
+		cmdAttempts(os.Args[2:])
+	case "result":
+		cmdResult(os.Args[2:])
 	case "cancel":
 		cmdCancel(os.Args[2:])
 	case "dlq-list":
@@ -53,9 +70,11 @@ Usage:
   taskforge-cli <command> [flags]
 
 Commands:
-  enqueue     Submit a job
+  enqueue     Submit a job (--wait blocks until it finishes)
   status      Get job status
-  cancel      Cancel a job (moves to DLQ with reason)
+  attempts    List a job's attempts
+  result      Get a completed job's result
+  cancel      Cancel a job (running jobs are stopped by their worker)
   dlq-list    List DLQ entries
   dlq-replay  Replay a DLQ job
 
@@ -75,12 +94,19 @@ func apiBase(fs *flag.FlagSet) *string {
 func cmdEnqueue(args []string) {
 	fs := flag.NewFlagSet("enqueue", flag.ExitOnError)
 	api := apiBase(fs)
+	queue := fs.String("queue", "", "Queue to submit to (default: the server's default queue)")
 	jobType := fs.String("job-type", "", "Job type")
 	idempotencyKey := fs.String("idempotency-key", "", "Idempotency key")
 	payload := fs.String("payload", "", "JSON payload string")
 	payloadFile := fs.String("payload-file", "", "Path to JSON payload file")
 	maxRetries := fs.Int("max-retries", 0, "Max retries (optional)")
 	maxAttempts := fs.Int("max-attempts", 0, "Max attempts (optional)")
+	priority := fs.Int("priority", 0, "Priority 0-9, higher runs first (optional)")
+	runAt := fs.String("run-at", "", "Run no earlier than this RFC3339 time (optional)")
+	delay := fs.String("delay", "", "Run after this delay, e.g. 15m (optional)")
+	timeout := fs.String("timeout", "", "Cancel an attempt that runs longer than this, e.g. 5m (optional, default: the worker's)")
+	wait := fs.Bool("wait", false, "Block until the job reaches COMPLETED, DLQ or CANCELED and print its final status and result")
+	waitTimeout := fs.Duration("wait-timeout", 5*time.Minute, "Give up waiting after this long (with --wait)")
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
@@ -92,6 +118,21 @@ func cmdEnqueue(args []string) {
 		os.Exit(2)
 	}
 
+	if *priority < 0 || *priority > 9 {
+		fmt.Fprintln(os.Stderr, "priority must be between 0 and 9")
+		os.Exit(2)
+	}
+	if *runAt != "" && *delay != "" {
+		fmt.Fprintln(os.Stderr, "use either run-at or delay, not both")
+		os.Exit(2)
+	}
+	if *runAt != "" {
+		if _, err := time.Parse(time.RFC3339, *runAt); err != nil {
+			fmt.Fprintln(os.Stderr, "run-at must be an RFC3339 time, e.g. 2026-01-02T15:04:05Z")
+			os.Exit(2)
+		}
+	}
+
 	raw, err := readPayload(*payload, *payloadFile)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -99,9 +140,14 @@ func cmdEnqueue(args []string) {
 	}
 
 	req := submitJobRequest{
+		Queue:          *queue,
 		JobType:        *jobType,
 		Payload:        json.RawMessage(raw),
 		IdempotencyKey: *idempotencyKey,
+		Priority:       *priority,
+		RunAt:          *runAt,
+		Delay:          *delay,
+		Timeout:        *timeout,
 	}
 	if *maxRetries > 0 {
 		req.MaxRetries = *maxRetries
@@ -121,7 +167,54 @@ func cmdEnqueue(args []string) {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(resp))
+	if !*wait {
+		fmt.Println(string(resp))
+		return
+	}
+
+	var submitted struct {
+		JobID string `json:"jobId"`
+	}
+	if err := json.Unmarshal(resp, &submitted); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	waitForJob(*api, submitted.JobID, *waitTimeout)
+}
+
+// waitForJob long-polls GET /jobs/{id}/wait until the job is done or timeout
+// passes, prints the last response, and exits non-zero unless the job
+// completed.
+func waitForJob(api string, jobID string, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		poll := min(time.Until(deadline), time.Minute).Round(time.Second)
+		if poll < time.Second {
+			poll = time.Second
+		}
+		u := api + "/jobs/" + jobID + "/wait?timeout=" + poll.String()
+		body, err := httpGetTimeout(u, poll+10*time.Second)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		var resp waitResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if resp.Done || !time.Now().Before(deadline) {
+			fmt.Println(string(body))
+			switch {
+			case !resp.Done:
+				fmt.Fprintf(os.Stderr, "timed out waiting for job %s (state %s)\n", jobID, resp.Job.State)
+				os.Exit(1)
+			case resp.Job.State != "COMPLETED":
+				os.Exit(1)
+			}
+			return
+		}
+	}
 }
 
 func cmdStatus(args []string) {
@@ -146,6 +239,54 @@ func cmdStatus(args []string) {
 	fmt.Println(string(resp))
 }
 
+func cmdAttempts(args []string) {
+	fs := flag.NewFlagSet("attempts", flag.ExitOnError)
+	api := apiBase(fs)
+	jobID := fs.String("id", "", "Job ID")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *jobID == "" {
+		fmt.Fprintln(os.Stderr, "id is required")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	resp, err := httpGet(*api + "/jobs/" + *jobID + "/attempts")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(resp))
+}
+
+func cmdResult(args []string) {
+	fs := flag.NewFlagSet("result", flag.ExitOnError)
+	api := apiBase(fs)
+	jobID := fs.String("id", "", "Job ID")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *jobID == "" {
+		fmt.Fprintln(os.Stderr, "id is required")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	resp, err := httpGet(*api + "/jobs/" + *jobID + "/result")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(resp) == 0 {
+		fmt.Fprintln(os.Stderr, "job completed without a result")
+		return
+	}
+	fmt.Println(string(resp))
+}
+
 func cmdCancel(args []string) {
 	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
 	api := apiBase(fs)
@@ -229,7 +370,11 @@ func readPayload(inline string, file string) ([]byte, error) {
 }
 
 func httpGet(url string) ([]byte, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+	return httpGetTimeout(url, 10*time.Second)
+}
+
+func httpGetTimeout(url string, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
 	resp, err := client.Get(url)
 	if err != nil {
 		return nil, err
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/pranavko12/taskforge/internal/config"
+	"github.com/pranavko12/taskforge/internal/metrics"
 	"github.com/pranavko12/taskforge/internal/queue"
 	"github.com/pranavko12/taskforge/internal/scheduler"
 	"github.com/pranavko12/taskforge/internal/storage"
@@ -40,32 +41,74 @@ func main() {
 	}
 	defer pg.Pool.Close()
 
-	rd := queue.NewRedis(cfg)
-	if err := rd.Ping(ctx); err != nil {
-		logger.Error("failed to connect redis", "err", err)
-		os.Exit(1)
+	store := scheduler.NewPostgresStore(pg.Pool)
+
+	var jobQueue scheduler.Queue
+	var reconcileTick <-chan time.Time
+	var reconciler *scheduler.QueueReconciler
+	if cfg.UsesRedis() {
+		rd := queue.NewRedis(cfg)
+		if err := rd.Ping(ctx); err != nil {
+			logger.Error("failed to connect redis", "err", err)
+			os.Exit(1)
+		}
+		jobQueue = rd
+		if cfg.ReconcileInterval > 0 {
+			reconciler = scheduler.NewQueueReconciler(store, rd, cfg.Queues)
+			reconcileTicker := time.NewTicker(cfg.ReconcileInterval)
+			defer reconcileTicker.Stop()
+			reconcileTick = reconcileTicker.C
+		}
 	}
 
-	store := scheduler.NewPostgresStore(pg.Pool)
-	s := scheduler.New(store, rd, cfg.QueueName)
+	s := scheduler.New(store, jobQueue, cfg.QueueName)
+	materializer := scheduler.NewCronMaterializer(store, jobQueue)
 	leaseStore := worker.NewPostgresStore(pg.Pool)
-	reaper := worker.NewLeaseReaper(leaseStore, rd, cfg.QueueName)
+	reaper := worker.NewLeaseReaper(leaseStore, jobQueue, cfg.QueueName)
+	reaper.SetMaxExpirations(cfg.LeaseExpiryLimit)
+	relay := scheduler.NewOutboxRelay(store, jobQueue)
+
+	go func() {
+		if err := metrics.Serve(ctx, cfg.MetricsAddr); err != nil {
+			logger.Error("metrics server stopped", "addr", cfg.MetricsAddr, "err", err)
+		}
+	}()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
+	outboxTicker := time.NewTicker(cfg.OutboxInterval)
+	defer outboxTicker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
+			if _, err := materializer.MaterializeDueRuns(ctx, time.Now().UTC()); err != nil {
+				logger.Error("materialize scheduled runs failed", "err", err)
+			}
+			if _, err := s.RetryFailedJobs(ctx, time.Now().UTC()); err != nil {
+				logger.Error("retry failed jobs failed", "err", err)
+			}
 			if _, err := s.EnqueueDueRetries(ctx, time.Now().UTC()); err != nil {
 				logger.Error("enqueue due retries failed", "err", err)
 			}
 			if _, err := reaper.RequeueExpiredLeases(ctx, time.Now().UTC()); err != nil {
 				logger.Error("requeue expired leases failed", "err", err)
 			}
+		case <-outboxTicker.C:
+			if _, err := relay.Relay(ctx, time.Now().UTC()); err != nil {
+				logger.Error("relay queue outbox failed", "err", err)
+			}
+		case <-reconcileTick:
+			pushed, removed, err := reconciler.Reconcile(ctx)
+			if err != nil {
+				logger.Error("reconcile redis queues failed", "err", err)
+			}
+			if pushed > 0 || removed > 0 {
+				logger.Info("reconciled redis queues", "pushed", pushed, "removed", removed)
+			}
 		case <-stop:
 			return
 		}
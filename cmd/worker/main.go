@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/pranavko12/taskforge/internal/config"
+	"github.com/pranavko12/taskforge/internal/metrics"
+	"github.com/pranavko12/taskforge/internal/queue"
 	"github.com/pranavko12/taskforge/internal/storage"
 	"github.com/pranavko12/taskforge/internal/telemetry"
 	"github.com/pranavko12/taskforge/internal/worker"
@@ -37,18 +39,72 @@ func main() {
 	defer pg.Pool.Close()
 
 	leaseStore := worker.NewPostgresStore(pg.Pool)
+	leaseStore.SetPriorityAging(cfg.PriorityAging)
 	leaseFor := 30 * time.Second
 	leaseID := "worker-" + strconv.FormatInt(time.Now().UnixNano(), 10)
 	loop := worker.NewLoop(leaseStore, cfg.QueueName, leaseID, leaseFor)
-	runner := worker.NewRunner(cfg.QueueName, worker.NewThrottler(cfg.QueueName, cfg.WorkerConcurrency, cfg.RateLimitPerSec), leaseStore)
+	loop.SetConcurrency(cfg.WorkerConcurrency)
+	loop.SetDrainTimeout(cfg.WorkerShutdown)
+	loop.SetJobTimeouts(cfg.WorkerJobTimeout, cfg.WorkerJobTimeouts)
+
+	// Each queue gets a throttler in the loop, which caps its in-flight jobs
+	// before leasing, and a runner that records its metrics and applies the
+	// rate limit.
+	loopQueues := make([]worker.LoopQueue, 0, len(cfg.WorkerQueues))
+	runners := make(map[string]*worker.Runner, len(cfg.WorkerQueues))
+	for _, q := range cfg.WorkerQueues {
+		limit := cfg.WorkerConcurrency
+		if q.Concurrency > 0 && q.Concurrency < limit {
+			limit = q.Concurrency
+		}
+		throttler := worker.NewThrottler(q.Name, limit, 0)
+		defer throttler.Close()
+		rateLimiter := worker.NewThrottler(q.Name, 0, cfg.RateLimitPerSec)
+		defer rateLimiter.Close()
+
+		loopQueues = append(loopQueues, worker.LoopQueue{Name: q.Name, Weight: q.Weight, Throttler: throttler})
+		runner := worker.NewRunner(q.Name, rateLimiter, leaseStore)
+		runner.SetRetryPanics(cfg.WorkerRetryPanics)
+		runners[q.Name] = runner
+	}
+	loop.SetQueues(worker.QueueMode(cfg.WorkerQueueMode), loopQueues)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	execute := func(execCtx context.Context, jobID string) error {
-		return runner.ExecuteJob(execCtx, jobID, 0, func(context.Context) error {
-			// Placeholder: plug real job-type dispatch here.
-			return nil
+	go func() {
+		if err := metrics.Serve(ctx, cfg.MetricsAddr); err != nil {
+			slog.Error("metrics server stopped", "addr", cfg.MetricsAddr, "err", err)
+		}
+	}()
+
+	switch cfg.WorkerWakeup {
+	case "redis":
+		rd := queue.NewRedis(cfg)
+		if err := rd.Ping(ctx); err != nil {
+			slog.Error("redis init error", "err", err)
+			os.Exit(1)
+		}
+		defer func() { _ = rd.Client.Close() }()
+		wakeups := queue.NewWakeups(rd, workerQueueNames(cfg))
+		go wakeups.Run(ctx)
+		loop.SetWaiter(wakeups, cfg.WorkerWakeupWait)
+	case "notify":
+		listener := storage.NewListener(pg.Pool, workerQueueNames(cfg))
+		go listener.Run(ctx)
+		loop.SetWaiter(listener, cfg.WorkerWakeupWait)
+	}
+
+	registry := worker.NewRegistry()
+	registry.Register(worker.WebhookDeliverJobType, worker.NewWebhookExecutor(nil, leaseStore).Handler())
+
+	execute := func(execCtx context.Context, job worker.Job) error {
+		var timeInQueue time.Duration
+		if !job.ReadyAt.IsZero() {
+			timeInQueue = time.Since(job.ReadyAt)
+		}
+		return runners[job.Queue].ExecuteJob(execCtx, job, timeInQueue, func(runCtx context.Context) error {
+			return registry.Dispatch(runCtx, job)
 		})
 	}
 
@@ -57,3 +113,11 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func workerQueueNames(cfg config.Config) []string {
+	queueNames := make([]string, 0, len(cfg.WorkerQueues))
+	for _, q := range cfg.WorkerQueues {
+		queueNames = append(queueNames, q.Name)
+	}
+	return queueNames
+}
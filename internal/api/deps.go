@@ -25,8 +25,10 @@ func (d *DefaultDependencyChecker) Check(ctx context.Context) error {
 	if err := d.store.Ping(ctx); err != nil {
 		return errors.New("postgres not ready")
 	}
+	// Without a queue the deployment is Postgres-only and Redis is not a
+	// dependency.
 	if d.queue == nil {
-		return errors.New("redis not ready")
+		return nil
 	}
 	if err := d.queue.Ping(ctx); err != nil {
 		return errors.New("redis not ready")
@@ -107,6 +107,45 @@ func TestDLQJobReason(t *testing.T) {
 	}
 }
 
+func TestCancelJobSignalsRunningJob(t *testing.T) {
+	store := fakeStore{running: true}
+	s := newTestServer(&store, &fakeQueue{})
+	req := httptest.NewRequest(http.MethodPost, "/jobs/job-1/cancel", bytes.NewBufferString(`{"reason":"user requested"}`))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	if store.cancelReason != "user requested" || store.dlqCalled {
+		t.Fatalf("expected only a cancel request, got reason=%q dlq=%v", store.cancelReason, store.dlqCalled)
+	}
+}
+
+func TestCancelJobCancelsPendingJob(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+	req := httptest.NewRequest(http.MethodPost, "/jobs/job-1/cancel", nil)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if store.cancelReason != "canceled" || store.dlqCalled {
+		t.Fatalf("expected a cancel without dlq, got reason=%q dlq=%v", store.cancelReason, store.dlqCalled)
+	}
+}
+
+func TestCancelJobRejectsFinishedJob(t *testing.T) {
+	store := fakeStore{cancelErr: errInvalidTransition}
+	s := newTestServer(&store, &fakeQueue{})
+	req := httptest.NewRequest(http.MethodPost, "/jobs/job-1/cancel", nil)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+}
+
 // Extend fakeStore with DLQ methods for tests.
 func (f fakeStore) InsertDLQEntry(ctx context.Context, jobID string, reason string) error {
 	return nil
@@ -123,6 +162,9 @@ func (f fakeStore) GetDLQEntry(ctx context.Context, jobID string) (DLQEntry, err
 	return f.getDLQEntryResp, nil
 }
 
-func (f fakeStore) ReplayDLQ(ctx context.Context, jobID string) error {
-	return f.replayErr
+func (f fakeStore) ReplayDLQ(ctx context.Context, jobID string) (string, error) {
+	if f.replayErr != nil {
+		return "", f.replayErr
+	}
+	return f.jobQueue, nil
 }
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/pranavko12/taskforge/internal/storage"
+)
+
+// streamBuffer is how many events a GET /events subscriber may fall behind
+// before it is dropped and has to resume with Last-Event-ID.
+const streamBuffer = 256
+
+// EventHub fans job state changes from a single storage.JobEventFeed out to
+// the requests waiting on them, so the number of waiters does not change the
+// number of database queries made while nothing happens.
+type EventHub struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+	streams map[*eventStream]struct{}
+	// highWater is the highest event ID published so far.
+	highWater int64
+}
+
+// eventStream is one GET /events subscriber. ch is closed when the hub drops
+// it. since is the hub's high-water ID when it subscribed; every event up to
+// it was published before, so only late commits below it can still arrive.
+type eventStream struct {
+	filter EventFilter
+	ch     chan JobStateEvent
+	since  int64
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{
+		waiters: map[string]map[chan struct{}]struct{}{},
+		streams: map[*eventStream]struct{}{},
+	}
+}
+
+// Publish wakes every waiter on n.JobID and sends the change to every stream
+// whose filter matches it.
+func (h *EventHub) Publish(n storage.JobEventNotice) {
+	ev := JobStateEvent{
+		ID:        n.ID,
+		JobID:     n.JobID,
+		Queue:     n.Queue,
+		JobType:   n.JobType,
+		FromState: n.FromState,
+		ToState:   n.State,
+		Actor:     n.Actor,
+		CreatedAt: n.At,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.highWater = max(h.highWater, n.ID)
+	for ch := range h.waiters[n.JobID] {
+		wake(ch)
+	}
+	for st := range h.streams {
+		if !st.filter.matches(ev) {
+			continue
+		}
+		select {
+		case st.ch <- ev:
+		default:
+			// A slow reader must not hold up the feed; it resumes from the
+			// log instead.
+			h.dropLocked(st)
+		}
+	}
+}
+
+// Resync wakes every waiter so each rechecks its job, and drops every stream
+// so it resumes from the log; the feed calls it after reconnecting because
+// changes made while it was down were not announced.
+func (h *EventHub) Resync() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, set := range h.waiters {
+		for ch := range set {
+			wake(ch)
+		}
+	}
+	for st := range h.streams {
+		h.dropLocked(st)
+	}
+}
+
+// watch registers a waiter on jobID. The returned channel receives a value
+// when the job may have changed; stop must be called when done waiting.
+func (h *EventHub) watch(jobID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	set := h.waiters[jobID]
+	if set == nil {
+		set = map[chan struct{}]struct{}{}
+		h.waiters[jobID] = set
+	}
+	set[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// A set is only dropped once empty, so ch is still in the current one.
+		set := h.waiters[jobID]
+		delete(set, ch)
+		if len(set) == 0 {
+			delete(h.waiters, jobID)
+		}
+	}
+}
+
+// subscribe registers a stream for the events matching f. unsubscribe must be
+// called when the stream ends.
+func (h *EventHub) subscribe(f EventFilter) *eventStream {
+	st := &eventStream{filter: f, ch: make(chan JobStateEvent, streamBuffer)}
+	h.mu.Lock()
+	st.since = h.highWater
+	h.streams[st] = struct{}{}
+	h.mu.Unlock()
+	return st
+}
+
+func (h *EventHub) unsubscribe(st *eventStream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.dropLocked(st)
+}
+
+func (h *EventHub) dropLocked(st *eventStream) {
+	if _, ok := h.streams[st]; !ok {
+		return
+	}
+	delete(h.streams, st)
+	close(st.ch)
+}
+
+func (f EventFilter) matches(ev JobStateEvent) bool {
+	return matchesAny(f.Queues, ev.Queue) &&
+		matchesAny(f.JobTypes, ev.JobType) &&
+		matchesAny(f.States, ev.ToState) &&
+		matchesAny(f.JobIDs, ev.JobID)
+}
+
+func matchesAny(values []string, v string) bool {
+	return len(values) == 0 || slices.Contains(values, v)
+}
+
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
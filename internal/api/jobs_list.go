@@ -32,16 +32,22 @@ func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
 	state := strings.TrimSpace(qp.Get("state"))
 	jobType := strings.TrimSpace(qp.Get("jobType"))
 	search := strings.TrimSpace(qp.Get("q"))
+	priority, err := parsePriorityFilter(qp.Get("priority"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_priority", err.Error(), nil)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 4*time.Second)
 	defer cancel()
 
 	items, total, err := s.store.QueryJobs(ctx, JobsQuery{
-		Limit:   limit,
-		Offset:  offset,
-		State:   state,
-		JobType: jobType,
-		Q:       search,
+		Limit:    limit,
+		Offset:   offset,
+		State:    state,
+		JobType:  jobType,
+		Q:        search,
+		Priority: priority,
 	})
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to list jobs", nil)
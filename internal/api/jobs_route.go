@@ -18,7 +18,7 @@ func (s *Server) retryJob(w http.ResponseWriter, r *http.Request, jobID string)
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	ok, err := s.store.RetryJob(ctx, jobID)
+	queueName, ok, err := s.store.RetryJob(ctx, jobID)
 	if err != nil {
 		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to retry job")
 		writeAPIError(w, status, code, message, nil)
@@ -29,12 +29,67 @@ func (s *Server) retryJob(w http.ResponseWriter, r *http.Request, jobID string)
 		return
 	}
 
-	if err := s.queue.Enqueue(ctx, s.queueName, jobID); err != nil {
-		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to enqueue job", nil)
+	s.publish(ctx, queueName, jobID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listJobAttempts(w http.ResponseWriter, r *http.Request, jobID string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	items, err := s.store.ListJobAttempts(ctx, jobID)
+	if err != nil {
+		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to list attempts")
+		writeAPIError(w, status, code, message, nil)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	writeJSON(w, http.StatusOK, JobAttemptsResponse{JobID: jobID, Items: items})
+}
+
+// getJobResult returns the stored result as-is with its content type. A
+// completed job without a result gets 204; a job that has not completed yet
+// gets 409 so callers can tell "no output" from "not done".
+func (s *Server) getJobResult(w http.ResponseWriter, r *http.Request, jobID string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	res, err := s.store.GetJobResult(ctx, jobID)
+	if err != nil {
+		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to fetch result")
+		writeAPIError(w, status, code, message, nil)
+		return
+	}
+	if res.State != StateCompleted {
+		writeAPIError(w, http.StatusConflict, "result_not_ready", "job has not completed", map[string]string{"state": res.State})
+		return
+	}
+	if res.Data == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	contentType := res.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(res.Data)
+}
+
+func (s *Server) listJobEvents(w http.ResponseWriter, r *http.Request, jobID string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	items, err := s.store.ListJobEvents(ctx, jobID)
+	if err != nil {
+		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to list events")
+		writeAPIError(w, status, code, message, nil)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, JobEventsResponse{JobID: jobID, Items: items})
 }
 
 func (s *Server) dlqJob(w http.ResponseWriter, r *http.Request, jobID string) {
@@ -86,14 +141,16 @@ func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request, jobID string)
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	ok, err := s.store.DLQJob(ctx, jobID, req.Reason)
+	state, err := s.store.CancelJob(ctx, jobID, req.Reason)
 	if err != nil {
 		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to cancel job")
 		writeAPIError(w, status, code, message, nil)
 		return
 	}
-	if !ok {
-		writeAPIError(w, http.StatusNotFound, "not_found", "not found", nil)
+	// A running job is only flagged; its worker stops the handler and
+	// finishes the job as CANCELED, so the request is accepted, not done.
+	if state == StateInProgress {
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
@@ -12,32 +12,32 @@ import (
 
 var metricsOnce sync.Once
 
-func metricsHandler(store Store, queue Queue, queueName string) http.Handler {
+func metricsHandler(store Store, queueNames []string) http.Handler {
 	metricsOnce.Do(func() {
 		reg, ok := prometheus.DefaultRegisterer.(*prometheus.Registry)
 		if ok {
 			metrics.Register(reg)
 		}
-		p := queueDLQProvider{store: store, queue: queue, queueName: queueName}
-		prometheus.MustRegister(metrics.NewQueueDLQCollector(queueName, p))
+		prometheus.MustRegister(metrics.NewQueueDLQCollector(queueNames, queueDLQProvider{store: store}))
 	})
 	return promhttp.Handler()
 }
 
 type queueDLQProvider struct {
-	store     Store
-	queue     Queue
-	queueName string
+	store Store
 }
 
-func (p queueDLQProvider) QueueDepth(ctx context.Context) (int64, error) {
-	return p.queue.QueueDepth(ctx, p.queueName)
+// QueueDepth counts ready jobs in Postgres. The Redis list is only a wakeup
+// hint that workers never pop, and it keeps entries until the reconciler
+// prunes them, so its length does not reflect outstanding work.
+func (p queueDLQProvider) QueueDepth(ctx context.Context, queueName string) (int64, error) {
+	return p.store.QueueDepth(ctx, queueName)
 }
 
-func (p queueDLQProvider) DLQCount(ctx context.Context) (int, error) {
+func (p queueDLQProvider) TerminalCounts(ctx context.Context) (metrics.TerminalCounts, error) {
 	stats, err := p.store.Stats(ctx)
 	if err != nil {
-		return 0, err
+		return metrics.TerminalCounts{}, err
 	}
-	return stats.DLQ, nil
+	return metrics.TerminalCounts{DLQ: stats.DLQ, Canceled: stats.Canceled}, nil
 }
@@ -6,6 +6,8 @@ import (
 )
 
 type SubmitJobRequest struct {
+	// Queue defaults to the server's QUEUE_NAME and must be a known queue.
+	Queue          string          `json:"queue,omitempty"`
 	JobType        string          `json:"jobType"`
 	Payload        json.RawMessage `json:"payload"`
 	IdempotencyKey string          `json:"idempotencyKey"`
@@ -15,6 +17,16 @@ type SubmitJobRequest struct {
 	Backoff        float64         `json:"backoff"`
 	MaxDelay       int             `json:"maxDelay"`
 	Jitter         bool            `json:"jitter"`
+	// Priority ranges from 0 (default) to 9; higher-priority jobs are leased first.
+	Priority int `json:"priority"`
+	// RunAt (RFC3339) or Delay (Go duration, e.g. "15m") defers the first run.
+	RunAt *time.Time `json:"runAt,omitempty"`
+	Delay string     `json:"delay,omitempty"`
+	// TimeoutMs or Timeout (Go duration, e.g. "5m") bounds each attempt; the
+	// worker cancels the handler's context when it passes. Zero uses the
+	// worker's default for the job type.
+	TimeoutMs int    `json:"timeoutMs,omitempty"`
+	Timeout   string `json:"timeout,omitempty"`
 	// Legacy aliases kept for backward compatibility.
 	InitialDelayMs    int     `json:"initialDelayMs"`
 	BackoffMultiplier float64 `json:"backoffMultiplier"`
@@ -27,8 +39,10 @@ type SubmitJobResponse struct {
 
 type JobStatusResponse struct {
 	JobID        string     `json:"jobId"`
+	Queue        string     `json:"queue"`
 	JobType      string     `json:"jobType"`
 	State        string     `json:"state"`
+	Priority     int        `json:"priority"`
 	RetryCount   int        `json:"retryCount"`
 	MaxRetries   int        `json:"maxRetries"`
 	MaxAttempts  int        `json:"maxAttempts"`
@@ -37,6 +51,7 @@ type JobStatusResponse struct {
 	Backoff      float64    `json:"backoff"`
 	MaxDelay     int        `json:"maxDelay"`
 	Jitter       bool       `json:"jitter"`
+	TimeoutMs    int        `json:"timeoutMs"`
 	NextRunAt    time.Time  `json:"nextRunAt"`
 	LastError    string     `json:"lastError"`
 	ScheduledAt  time.Time  `json:"scheduledAt"`
@@ -46,6 +61,86 @@ type JobStatusResponse struct {
 	CreatedAt    time.Time  `json:"createdAt"`
 	UpdatedAt    time.Time  `json:"updatedAt"`
 	Traceparent  string     `json:"traceparent,omitempty"`
+	// Populated by executors that call out over HTTP, e.g. webhook.deliver.
+	LastResponseStatus  *int   `json:"lastResponseStatus,omitempty"`
+	LastResponseExcerpt string `json:"lastResponseExcerpt,omitempty"`
+	// HasResult reports whether GET /jobs/{id}/result has a body.
+	HasResult bool `json:"hasResult"`
+}
+
+// JobResult is the stored output of a job. Data is nil until a handler sets
+// a result and the job completes.
+type JobResult struct {
+	State       string
+	Data        []byte
+	ContentType string
+}
+
+// JobWaitResponse is returned by GET /jobs/{id}/wait. Done is false when the
+// wait timed out, or the server began shutting down, before the job reached
+// COMPLETED, DLQ or CANCELED; Job is then the latest status.
+type JobWaitResponse struct {
+	Job               JobStatusResponse `json:"job"`
+	Done              bool              `json:"done"`
+	Result            json.RawMessage   `json:"result,omitempty"`
+	ResultContentType string            `json:"resultContentType,omitempty"`
+}
+
+// JobAttempt is one lease of a job by a worker. FinishedAt and DurationMs are
+// unset while the attempt is running.
+type JobAttempt struct {
+	Attempt      int        `json:"attempt"`
+	LeaseOwner   string     `json:"leaseOwner"`
+	LeaseToken   int64      `json:"leaseToken"`
+	Outcome      string     `json:"outcome"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+	DurationMs   *int64     `json:"durationMs,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	FailureClass string     `json:"failureClass,omitempty"`
+}
+
+type JobAttemptsResponse struct {
+	JobID string       `json:"jobId"`
+	Items []JobAttempt `json:"items"`
+}
+
+// JobEvent is one state change of a job. FromState is empty for the event
+// recorded when the job was created.
+type JobEvent struct {
+	ID        int64     `json:"id"`
+	FromState string    `json:"fromState,omitempty"`
+	ToState   string    `json:"toState"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type JobEventsResponse struct {
+	JobID string     `json:"jobId"`
+	Items []JobEvent `json:"items"`
+}
+
+// JobStateEvent is a state change as pushed by GET /events. Unlike JobEvent it
+// names the job and carries the fields the stream filters on.
+type JobStateEvent struct {
+	ID        int64     `json:"id"`
+	JobID     string    `json:"jobId"`
+	Queue     string    `json:"queue"`
+	JobType   string    `json:"jobType"`
+	FromState string    `json:"fromState,omitempty"`
+	ToState   string    `json:"toState"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EventFilter selects the events GET /events sends. Each non-empty list must
+// contain the event's value; empty lists match everything.
+type EventFilter struct {
+	Queues   []string
+	JobTypes []string
+	States   []string
+	JobIDs   []string
 }
 
 type JobsListResponse struct {
@@ -62,6 +157,8 @@ type JobsQuery struct {
 	State   string
 	JobType string
 	Q       string
+	// Priority filters on an exact priority when non-nil.
+	Priority *int
 }
 
 type DLQEntry struct {
@@ -90,3 +187,52 @@ type DLQInspectResponse struct {
 	Entry DLQEntry          `json:"entry"`
 	Job   JobStatusResponse `json:"job"`
 }
+
+// ScheduleRequest creates or replaces a recurring schedule. Each fire time
+// materializes one job built from JobType, Payload and the retry policy.
+type ScheduleRequest struct {
+	Name     string          `json:"name"`
+	Cron     string          `json:"cron"`
+	Timezone string          `json:"timezone"`
+	Queue    string          `json:"queue,omitempty"`
+	JobType  string          `json:"jobType"`
+	Payload  json.RawMessage `json:"payload"`
+	// MissedRunPolicy is one of skip (default), run_once or catch_up.
+	MissedRunPolicy string  `json:"missedRunPolicy"`
+	Enabled         *bool   `json:"enabled,omitempty"`
+	Priority        int     `json:"priority"`
+	MaxAttempts     int     `json:"maxAttempts"`
+	InitialDelay    int     `json:"initialDelay"`
+	Backoff         float64 `json:"backoff"`
+	MaxDelay        int     `json:"maxDelay"`
+	Jitter          bool    `json:"jitter"`
+}
+
+type ScheduleResponse struct {
+	ScheduleID      string          `json:"scheduleId"`
+	Name            string          `json:"name"`
+	Cron            string          `json:"cron"`
+	Timezone        string          `json:"timezone"`
+	Queue           string          `json:"queue"`
+	JobType         string          `json:"jobType"`
+	Payload         json.RawMessage `json:"payload"`
+	MissedRunPolicy string          `json:"missedRunPolicy"`
+	Enabled         bool            `json:"enabled"`
+	Priority        int             `json:"priority"`
+	MaxAttempts     int             `json:"maxAttempts"`
+	InitialDelay    int             `json:"initialDelay"`
+	Backoff         float64         `json:"backoff"`
+	MaxDelay        int             `json:"maxDelay"`
+	Jitter          bool            `json:"jitter"`
+	NextFireAt      time.Time       `json:"nextFireAt"`
+	LastFireAt      *time.Time      `json:"lastFireAt,omitempty"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+}
+
+type SchedulesListResponse struct {
+	Items  []ScheduleResponse `json:"items"`
+	Total  int                `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
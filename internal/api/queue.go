@@ -2,8 +2,24 @@ package api
 
 import "context"
 
+// Queue publishes job IDs to Redis after they are persisted. It is optional:
+// workers lease from Postgres, so a Server without a Queue runs in
+// Postgres-only mode and skips publishing.
 type Queue interface {
 	Ping(ctx context.Context) error
 	Enqueue(ctx context.Context, queueName string, jobID string) error
-	QueueDepth(ctx context.Context, queueName string) (int64, error)
+}
+
+// publish is the fast path for a job whose outbox entry was just committed.
+// On failure the scheduler's outbox relay publishes it later, so the request
+// still succeeds.
+func (s *Server) publish(ctx context.Context, queueName string, jobID string) {
+	if s.queue == nil {
+		return
+	}
+	if err := s.queue.Enqueue(ctx, queueName, jobID); err != nil {
+		s.logger.Warn("publish job failed, leaving it to the outbox relay", "job_id", jobID, "queue", queueName, "err", err)
+		return
+	}
+	s.store.MarkJobPublished(ctx, jobID)
 }
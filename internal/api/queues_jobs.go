@@ -46,17 +46,23 @@ func (s *Server) queuesSubroutes(w http.ResponseWriter, r *http.Request) {
 	status := strings.TrimSpace(qp.Get("status"))
 	jobType := strings.TrimSpace(qp.Get("jobType"))
 	search := strings.TrimSpace(qp.Get("q"))
+	priority, err := parsePriorityFilter(qp.Get("priority"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_priority", err.Error(), nil)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 4*time.Second)
 	defer cancel()
 
 	items, total, err := s.store.QueryJobs(ctx, JobsQuery{
-		Limit:   limit,
-		Offset:  offset,
-		Queue:   queueName,
-		State:   status,
-		JobType: jobType,
-		Q:       search,
+		Limit:    limit,
+		Offset:   offset,
+		Queue:    queueName,
+		State:    status,
+		JobType:  jobType,
+		Q:        search,
+		Priority: priority,
 	})
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to list jobs", nil)
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pranavko12/taskforge/internal/cron"
+)
+
+const (
+	MissedRunSkip    = "skip"
+	MissedRunOnce    = "run_once"
+	MissedRunCatchUp = "catch_up"
+)
+
+func (s *Server) schedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.createSchedule(w, r)
+	case http.MethodGet:
+		s.listSchedules(w, r)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "invalid_method", "method not allowed", nil)
+	}
+}
+
+func (s *Server) schedulesSubroutes(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/schedules/"), "/")
+	if id == "" {
+		writeAPIError(w, http.StatusBadRequest, "missing_schedule_id", "missing schedule id", nil)
+		return
+	}
+	if strings.Contains(id, "/") {
+		writeAPIError(w, http.StatusNotFound, "not_found", "not found", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getSchedule(w, r, id)
+	case http.MethodPut:
+		s.updateSchedule(w, r, id)
+	case http.MethodDelete:
+		s.deleteSchedule(w, r, id)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "invalid_method", "method not allowed", nil)
+	}
+}
+
+func (s *Server) createSchedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", "invalid json", err.Error())
+		return
+	}
+	sched, ok := s.buildSchedule(w, req, time.Now().UTC())
+	if !ok {
+		return
+	}
+	sched.ScheduleID = uuid.New().String()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	created, err := s.store.CreateSchedule(ctx, sched)
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeAPIError(w, http.StatusConflict, "schedule_exists", "a schedule with this name already exists", nil)
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to create schedule", nil)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *Server) listSchedules(w http.ResponseWriter, r *http.Request) {
+	qp := r.URL.Query()
+	limit := parseInt(qp.Get("limit"), defaultListLimit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	offset := parseInt(qp.Get("offset"), 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 4*time.Second)
+	defer cancel()
+
+	items, total, err := s.store.ListSchedules(ctx, limit, offset)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to list schedules", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, SchedulesListResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (s *Server) getSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	sched, err := s.store.GetSchedule(ctx, id)
+	if err != nil {
+		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to fetch schedule")
+		writeAPIError(w, status, code, message, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, sched)
+}
+
+// updateSchedule replaces the schedule definition. The next fire time is
+// recomputed from now, so edits never trigger a burst of missed runs.
+func (s *Server) updateSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	var req ScheduleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", "invalid json", err.Error())
+		return
+	}
+	sched, ok := s.buildSchedule(w, req, time.Now().UTC())
+	if !ok {
+		return
+	}
+	sched.ScheduleID = id
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	updated, err := s.store.UpdateSchedule(ctx, sched)
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeAPIError(w, http.StatusConflict, "schedule_exists", "a schedule with this name already exists", nil)
+			return
+		}
+		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to update schedule")
+		writeAPIError(w, status, code, message, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deleteSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := s.store.DeleteSchedule(ctx, id); err != nil {
+		status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to delete schedule")
+		writeAPIError(w, status, code, message, nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// buildSchedule validates req and fills in defaults. On failure it writes the
+// error response and returns false.
+func (s *Server) buildSchedule(w http.ResponseWriter, req ScheduleRequest, now time.Time) (ScheduleResponse, bool) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Cron = strings.TrimSpace(req.Cron)
+	req.JobType = strings.TrimSpace(req.JobType)
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	req.MissedRunPolicy = strings.TrimSpace(req.MissedRunPolicy)
+
+	if req.Name == "" || req.Cron == "" || req.JobType == "" || len(req.Payload) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "missing_required_fields", "missing required fields", nil)
+		return ScheduleResponse{}, false
+	}
+
+	expr, err := cron.Parse(req.Cron)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_schedule", fmt.Sprintf("invalid cron expression: %v", err), nil)
+		return ScheduleResponse{}, false
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(req.Timezone)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_schedule", fmt.Sprintf("unknown timezone %q", req.Timezone), nil)
+		return ScheduleResponse{}, false
+	}
+	queueName, ok := s.resolveQueue(req.Queue)
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "unknown_queue", fmt.Sprintf("unknown queue %q", req.Queue), nil)
+		return ScheduleResponse{}, false
+	}
+	next := expr.Next(now.In(loc))
+	if next.IsZero() {
+		writeAPIError(w, http.StatusBadRequest, "invalid_schedule", "cron expression never fires", nil)
+		return ScheduleResponse{}, false
+	}
+
+	if err := validatePriority(req.Priority); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_priority", err.Error(), nil)
+		return ScheduleResponse{}, false
+	}
+
+	switch req.MissedRunPolicy {
+	case "":
+		req.MissedRunPolicy = MissedRunSkip
+	case MissedRunSkip, MissedRunOnce, MissedRunCatchUp:
+	default:
+		writeAPIError(w, http.StatusBadRequest, "invalid_schedule", "missedRunPolicy must be skip, run_once or catch_up", nil)
+		return ScheduleResponse{}, false
+	}
+
+	policy := SubmitJobRequest{
+		MaxAttempts:  req.MaxAttempts,
+		InitialDelay: req.InitialDelay,
+		Backoff:      req.Backoff,
+		MaxDelay:     req.MaxDelay,
+	}
+	if err := applyRetryPolicyDefaults(&policy); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_retry_policy", err.Error(), nil)
+		return ScheduleResponse{}, false
+	}
+
+	if req.JobType == WebhookDeliverJobType {
+		if err := validateWebhookDeliverPayload(req.Payload); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_payload", err.Error(), nil)
+			return ScheduleResponse{}, false
+		}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return ScheduleResponse{
+		Name:            req.Name,
+		Cron:            req.Cron,
+		Timezone:        req.Timezone,
+		Queue:           queueName,
+		JobType:         req.JobType,
+		Payload:         req.Payload,
+		MissedRunPolicy: req.MissedRunPolicy,
+		Enabled:         enabled,
+		Priority:        req.Priority,
+		MaxAttempts:     policy.MaxAttempts,
+		InitialDelay:    policy.InitialDelay,
+		Backoff:         policy.Backoff,
+		MaxDelay:        policy.MaxDelay,
+		Jitter:          req.Jitter,
+		NextFireAt:      next.UTC(),
+	}, true
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateScheduleAppliesDefaults(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+
+	body := `{"name":"nightly-report","cron":"0 2 * * *","timezone":"Europe/Berlin","jobType":"report","payload":{"kind":"daily"}}`
+	req := httptest.NewRequest(http.MethodPost, "/schedules", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ScheduleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ScheduleID == "" || resp.Queue != "jobs:ready" {
+		t.Fatalf("unexpected schedule: %+v", resp)
+	}
+	if resp.MissedRunPolicy != MissedRunSkip || !resp.Enabled || resp.MaxAttempts != 5 {
+		t.Fatalf("expected defaults, got %+v", resp)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if local := resp.NextFireAt.In(berlin); local.Hour() != 2 || local.Minute() != 0 {
+		t.Fatalf("expected next fire at 02:00 Berlin, got %s", local)
+	}
+	if !resp.NextFireAt.After(time.Now()) {
+		t.Fatalf("expected next fire in the future, got %s", resp.NextFireAt)
+	}
+}
+
+func TestCreateScheduleRejectsInvalidInput(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"missing cron", `{"name":"a","jobType":"t","payload":{}}`, "missing_required_fields"},
+		{"bad cron", `{"name":"a","cron":"61 * * * *","jobType":"t","payload":{}}`, "invalid_schedule"},
+		{"never fires", `{"name":"a","cron":"0 0 30 2 *","jobType":"t","payload":{}}`, "invalid_schedule"},
+		{"bad timezone", `{"name":"a","cron":"@daily","timezone":"Mars/Olympus","jobType":"t","payload":{}}`, "invalid_schedule"},
+		{"bad policy", `{"name":"a","cron":"@daily","missedRunPolicy":"sometimes","jobType":"t","payload":{}}`, "invalid_schedule"},
+		{"bad retry policy", `{"name":"a","cron":"@daily","jobType":"t","payload":{},"backoff":0.5}`, "invalid_retry_policy"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(&fakeStore{}, &fakeQueue{})
+			req := httptest.NewRequest(http.MethodPost, "/schedules", bytes.NewBufferString(tc.body))
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+			assertAPIError(t, rec, http.StatusBadRequest, tc.code)
+		})
+	}
+}
+
+func TestScheduleCRUD(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+
+	body := `{"name":"hourly","cron":"@hourly","jobType":"sync","payload":{},"missedRunPolicy":"catch_up"}`
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/schedules", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	var created ScheduleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/schedules", bytes.NewBufferString(body)))
+	assertAPIError(t, rec, http.StatusConflict, "schedule_exists")
+
+	update := `{"name":"hourly","cron":"*/5 * * * *","jobType":"sync","payload":{},"enabled":false}`
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/schedules/"+created.ScheduleID, bytes.NewBufferString(update)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedules/"+created.ScheduleID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got ScheduleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode get response: %v", err)
+	}
+	if got.Cron != "*/5 * * * *" || got.Enabled || got.MissedRunPolicy != MissedRunSkip {
+		t.Fatalf("expected update to replace the schedule, got %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedules", nil))
+	var list SchedulesListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode list response: %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 {
+		t.Fatalf("unexpected list response: %+v", list)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/schedules/"+created.ScheduleID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedules/"+created.ScheduleID, nil))
+	assertAPIError(t, rec, http.StatusNotFound, "not_found")
+}
+
+// Extend fakeStore with schedule methods for tests.
+func (f *fakeStore) CreateSchedule(ctx context.Context, sched ScheduleResponse) (ScheduleResponse, error) {
+	if f.schedules == nil {
+		f.schedules = make(map[string]ScheduleResponse)
+	}
+	for _, existing := range f.schedules {
+		if existing.Name == sched.Name {
+			return ScheduleResponse{}, errUnique
+		}
+	}
+	f.schedules[sched.ScheduleID] = sched
+	return sched, nil
+}
+
+func (f *fakeStore) GetSchedule(ctx context.Context, scheduleID string) (ScheduleResponse, error) {
+	sched, ok := f.schedules[scheduleID]
+	if !ok {
+		return ScheduleResponse{}, errNotFound
+	}
+	return sched, nil
+}
+
+func (f *fakeStore) ListSchedules(ctx context.Context, limit, offset int) ([]ScheduleResponse, int, error) {
+	var items []ScheduleResponse
+	for _, sched := range f.schedules {
+		items = append(items, sched)
+	}
+	return items, len(items), nil
+}
+
+func (f *fakeStore) UpdateSchedule(ctx context.Context, sched ScheduleResponse) (ScheduleResponse, error) {
+	if _, ok := f.schedules[sched.ScheduleID]; !ok {
+		return ScheduleResponse{}, errNotFound
+	}
+	f.schedules[sched.ScheduleID] = sched
+	return sched, nil
+}
+
+func (f *fakeStore) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	if _, ok := f.schedules[scheduleID]; !ok {
+		return errNotFound
+	}
+	delete(f.schedules, scheduleID)
+	return nil
+}
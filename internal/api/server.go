@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,20 +21,35 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/pranavko12/taskforge/internal/config"
+	"github.com/pranavko12/taskforge/internal/metrics"
 )
 
 const maxJSONBody = 1 << 20 // 1 MiB
 
+const (
+	minPriority = 0
+	maxPriority = 9
+)
+
+// maxJobTimeout caps a job's execution timeout.
+const maxJobTimeout = 24 * time.Hour
+
 type Server struct {
 	store     Store
 	queue     Queue
 	deps      DependencyChecker
 	logger    *slog.Logger
 	queueName string
+	queues    map[string]bool
 	uiDir     string
 	http      *http.Server
 	handler   http.Handler
 	tracer    trace.Tracer
+	// events wakes long-poll waiters and feeds GET /events; nil falls back
+	// to polling and disables the stream.
+	events *EventHub
+	// closing is closed on Shutdown so open streams end.
+	closing chan struct{}
 }
 
 func NewServer(cfg config.Config, store Store, queue Queue, deps DependencyChecker, logger *slog.Logger) *Server {
@@ -44,7 +61,9 @@ func NewServer(cfg config.Config, store Store, queue Queue, deps DependencyCheck
 		deps:      deps,
 		logger:    logger,
 		queueName: cfg.QueueName,
+		queues:    map[string]bool{cfg.QueueName: true},
 		uiDir:     cfg.UIDir,
+		closing:   make(chan struct{}),
 		http: &http.Server{
 			Addr:              cfg.HTTPAddr,
 			ReadHeaderTimeout: 5 * time.Second,
@@ -54,6 +73,9 @@ func NewServer(cfg config.Config, store Store, queue Queue, deps DependencyCheck
 		},
 	}
 
+	for _, q := range cfg.Queues {
+		s.queues[q] = true
+	}
 	if s.logger == nil {
 		s.logger = slog.Default()
 	}
@@ -61,6 +83,7 @@ func NewServer(cfg config.Config, store Store, queue Queue, deps DependencyCheck
 		s.deps = NewDependencyChecker(s.store, s.queue)
 	}
 	s.tracer = otel.Tracer("taskforge/api")
+	s.http.RegisterOnShutdown(func() { close(s.closing) })
 
 	mux.HandleFunc("/healthz", s.healthz)
 	mux.HandleFunc("/readyz", s.readyz)
@@ -71,10 +94,18 @@ func NewServer(cfg config.Config, store Store, queue Queue, deps DependencyCheck
 	mux.HandleFunc("/queues/", s.queuesSubroutes)
 	mux.HandleFunc("/dlq", s.dlq)
 	mux.HandleFunc("/dlq/", s.dlqSubroutes)
+	mux.HandleFunc("/schedules", s.schedules)
+	mux.HandleFunc("/schedules/", s.schedulesSubroutes)
+	// Implemented in stream.go
+	mux.HandleFunc("/events", s.streamEvents)
 
 	// Implemented in stats.go
 	mux.HandleFunc("/stats", s.stats)
-	mux.Handle("/metrics", metricsHandler(s.store, s.queue, s.queueName))
+	queueNames := cfg.Queues
+	if len(queueNames) == 0 {
+		queueNames = []string{s.queueName}
+	}
+	mux.Handle("/metrics", metricsHandler(s.store, queueNames))
 
 	// Prefer embedded UI if available; fallback to disk.
 	if h, err := uiHandler(); err == nil {
@@ -89,6 +120,13 @@ func NewServer(cfg config.Config, store Store, queue Queue, deps DependencyCheck
 	return s
 }
 
+// SetEventHub makes GET /jobs/{id}/wait block on hub instead of polling the
+// store and enables GET /events. The caller feeds hub from a
+// storage.JobEventFeed.
+func (s *Server) SetEventHub(hub *EventHub) {
+	s.events = hub
+}
+
 func (s *Server) Start() error {
 	return s.http.ListenAndServe()
 }
@@ -170,6 +208,25 @@ func (s *Server) jobsSubroutes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) == 2 && (parts[1] == "attempts" || parts[1] == "events" || parts[1] == "result" || parts[1] == "wait") {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "invalid_method", "method not allowed", nil)
+			return
+		}
+		// Implemented in jobs_route.go
+		switch parts[1] {
+		case "attempts":
+			s.listJobAttempts(w, r, id)
+		case "events":
+			s.listJobEvents(w, r, id)
+		case "wait":
+			s.waitJob(w, r, id)
+		default:
+			s.getJobResult(w, r, id)
+		}
+		return
+	}
+
 	if len(parts) == 2 {
 		if r.Method != http.MethodPost {
 			writeAPIError(w, http.StatusMethodNotAllowed, "invalid_method", "method not allowed", nil)
@@ -280,15 +337,13 @@ func (s *Server) dlqSubroutes(w http.ResponseWriter, r *http.Request) {
 		}
 		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 		defer cancel()
-		if err := s.store.ReplayDLQ(ctx, id); err != nil {
+		queueName, err := s.store.ReplayDLQ(ctx, id)
+		if err != nil {
 			status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to replay job")
 			writeAPIError(w, status, code, message, nil)
 			return
 		}
-		if err := s.queue.Enqueue(ctx, s.queueName, id); err != nil {
-			writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to enqueue job", nil)
-			return
-		}
+		s.publish(ctx, queueName, id)
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
@@ -305,6 +360,12 @@ func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
 
 	req.JobType = strings.TrimSpace(req.JobType)
 	req.IdempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+	queueName, ok := s.resolveQueue(req.Queue)
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "unknown_queue", fmt.Sprintf("unknown queue %q", req.Queue), nil)
+		return
+	}
+	req.Queue = queueName
 
 	if req.JobType == "" || len(req.Payload) == 0 || req.IdempotencyKey == "" {
 		writeAPIError(w, http.StatusBadRequest, "missing_required_fields", "missing required fields", nil)
@@ -314,6 +375,18 @@ func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusBadRequest, "invalid_retry_policy", err.Error(), nil)
 		return
 	}
+	if err := validatePriority(req.Priority); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_priority", err.Error(), nil)
+		return
+	}
+	if err := resolveRunAt(&req, time.Now().UTC()); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_schedule", err.Error(), nil)
+		return
+	}
+	if err := resolveTimeout(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_timeout", err.Error(), nil)
+		return
+	}
 
 	// Make webhook jobs safe-by-default.
 	if req.JobType == WebhookDeliverJobType {
@@ -330,11 +403,11 @@ func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
 
 	traceparent := traceparentFromContext(r.Context())
 	if span := trace.SpanFromContext(r.Context()); span != nil {
-		span.SetAttributes(attribute.String("job_id", jobID), attribute.String("queue", s.queueName))
+		span.SetAttributes(attribute.String("job_id", jobID), attribute.String("queue", queueName))
 	}
-	if err := s.store.InsertJob(ctx, jobID, req, traceparent, s.queueName); err != nil {
+	if err := s.store.InsertJob(ctx, jobID, req, traceparent, queueName); err != nil {
 		if isUniqueViolation(err) {
-			existing, getErr := s.store.GetJobByIdempotencyKey(ctx, req.IdempotencyKey, s.queueName)
+			existing, getErr := s.store.GetJobByIdempotencyKey(ctx, req.IdempotencyKey, queueName)
 			if getErr != nil {
 				writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to load existing job", nil)
 				return
@@ -345,11 +418,9 @@ func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to persist job", nil)
 		return
 	}
+	metrics.IncJobsSubmitted(queueName, req.Priority)
 
-	if err := s.queue.Enqueue(ctx, s.queueName, jobID); err != nil {
-		writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to enqueue job", nil)
-		return
-	}
+	s.publish(ctx, queueName, jobID)
 
 	writeJSON(w, http.StatusAccepted, SubmitJobResponse{JobID: jobID})
 }
@@ -368,6 +439,16 @@ func (s *Server) getJobByID(w http.ResponseWriter, r *http.Request, id string) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// resolveQueue maps an empty queue to the default and reports whether the
+// queue is known to this server.
+func (s *Server) resolveQueue(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return s.queueName, true
+	}
+	return name, s.queues[name]
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -404,6 +485,78 @@ func traceparentFromContext(ctx context.Context) string {
 	return carrier.Get("traceparent")
 }
 
+// resolveRunAt folds Delay into RunAt so the store only deals with an absolute
+// time. A nil RunAt means run immediately.
+func resolveRunAt(req *SubmitJobRequest, now time.Time) error {
+	req.Delay = strings.TrimSpace(req.Delay)
+	if req.RunAt != nil && req.Delay != "" {
+		return errors.New("use either runAt or delay, not both")
+	}
+	if req.Delay != "" {
+		d, err := time.ParseDuration(req.Delay)
+		if err != nil {
+			return errors.New("delay must be a duration like 30s or 15m")
+		}
+		if d < 0 {
+			return errors.New("delay must be >= 0")
+		}
+		runAt := now.Add(d)
+		req.RunAt = &runAt
+		req.Delay = ""
+	}
+	if req.RunAt != nil {
+		runAt := req.RunAt.UTC()
+		req.RunAt = &runAt
+	}
+	return nil
+}
+
+// resolveTimeout folds Timeout into TimeoutMs, which is what the store keeps.
+func resolveTimeout(req *SubmitJobRequest) error {
+	req.Timeout = strings.TrimSpace(req.Timeout)
+	if req.Timeout != "" && req.TimeoutMs != 0 {
+		return errors.New("use either timeout or timeoutMs, not both")
+	}
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil {
+			return errors.New("timeout must be a duration like 30s or 15m")
+		}
+		if d < time.Millisecond || d > maxJobTimeout {
+			return fmt.Errorf("timeout must be between 1ms and %s", maxJobTimeout)
+		}
+		req.TimeoutMs = int(d / time.Millisecond)
+		req.Timeout = ""
+	}
+	if req.TimeoutMs < 0 || time.Duration(req.TimeoutMs)*time.Millisecond > maxJobTimeout {
+		return fmt.Errorf("timeoutMs must be between 0 and %d", maxJobTimeout.Milliseconds())
+	}
+	return nil
+}
+
+func validatePriority(p int) error {
+	if p < minPriority || p > maxPriority {
+		return fmt.Errorf("priority must be between %d and %d", minPriority, maxPriority)
+	}
+	return nil
+}
+
+// parsePriorityFilter parses the optional priority list filter.
+func parsePriorityFilter(v string) (*int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	p, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("priority must be an integer between %d and %d", minPriority, maxPriority)
+	}
+	if err := validatePriority(p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 func applyRetryPolicyDefaults(req *SubmitJobRequest) error {
 	if req.MaxAttempts <= 0 {
 		if req.MaxRetries > 0 {
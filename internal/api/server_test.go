@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -9,9 +10,16 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pranavko12/taskforge/internal/config"
+	"github.com/pranavko12/taskforge/internal/metrics"
+	"github.com/pranavko12/taskforge/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestHealthzAlwaysOK(t *testing.T) {
@@ -63,6 +71,56 @@ func TestReadyzDependsOnDeps(t *testing.T) {
 	}
 }
 
+func TestPostgresOnlyModeWithoutQueue(t *testing.T) {
+	store := fakeStore{retryOK: true}
+	s := newTestServer(&store, nil)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected readyz 200 without redis, got %d", rec.Code)
+	}
+
+	body := `{"jobType":"email","payload":{},"idempotencyKey":"k"}`
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected submit 202, got %d", rec.Code)
+	}
+
+	for _, path := range []string{"/jobs/job-1/retry", "/dlq/job-1/replay"} {
+		rec = httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("%s: expected 204, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestSubmitJobSucceedsWhenPublishFails(t *testing.T) {
+	store := fakeStore{}
+	q := &fakeQueue{enqueueErr: errTest}
+	s := newTestServer(&store, q)
+
+	body := `{"jobType":"email","payload":{},"idempotencyKey":"k"}`
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 once the job is persisted, got %d", rec.Code)
+	}
+	if len(store.published) != 0 {
+		t.Fatalf("expected outbox entry left for the relay, got published %v", store.published)
+	}
+
+	q.enqueueErr = nil
+	body = `{"jobType":"email","payload":{},"idempotencyKey":"k2"}`
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusAccepted || len(store.published) != 1 {
+		t.Fatalf("expected direct publish to mark the outbox entry, got %d published=%v", rec.Code, store.published)
+	}
+}
+
 func TestRequestIDHeaderSet(t *testing.T) {
 	q := &fakeQueue{}
 	s := newTestServer(&fakeStore{}, q)
@@ -198,6 +256,178 @@ func TestDuplicateSubmissionsReturnExistingJob(t *testing.T) {
 	}
 }
 
+func TestSubmitJobWithDelayResolvesRunAt(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+
+	before := time.Now().UTC()
+	body := `{"jobType":"demo","payload":{"a":1},"idempotencyKey":"later","delay":"15m"}`
+	req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	runAt := store.lastInsert.RunAt
+	if runAt == nil {
+		t.Fatal("expected runAt to be resolved from delay")
+	}
+	if runAt.Before(before.Add(15*time.Minute)) || runAt.After(time.Now().UTC().Add(15*time.Minute)) {
+		t.Fatalf("expected runAt ~15m from now, got %v", runAt)
+	}
+}
+
+func TestSubmitJobWithRunAt(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+
+	body := `{"jobType":"demo","payload":{"a":1},"idempotencyKey":"later","runAt":"2030-01-02T03:04:05+02:00"}`
+	req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	want := time.Date(2030, 1, 2, 1, 4, 5, 0, time.UTC)
+	if store.lastInsert.RunAt == nil || !store.lastInsert.RunAt.Equal(want) {
+		t.Fatalf("expected runAt %v, got %v", want, store.lastInsert.RunAt)
+	}
+}
+
+func TestSubmitJobRejectsInvalidSchedule(t *testing.T) {
+	cases := []string{
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","delay":"soon"}`,
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","delay":"-1m"}`,
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","delay":"1m","runAt":"2030-01-02T03:04:05Z"}`,
+	}
+	for _, body := range cases {
+		s := newTestServer(&fakeStore{}, &fakeQueue{})
+		req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		assertAPIError(t, rec, http.StatusBadRequest, "invalid_schedule")
+	}
+}
+
+func TestSubmitJobTimeout(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+
+	body := `{"jobType":"demo","payload":{"a":1},"idempotencyKey":"bounded","timeout":"90s"}`
+	req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	if store.lastInsert.TimeoutMs != 90000 {
+		t.Fatalf("expected timeoutMs 90000, got %d", store.lastInsert.TimeoutMs)
+	}
+
+	cases := []string{
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","timeout":"forever"}`,
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","timeout":"48h"}`,
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","timeoutMs":-1}`,
+		`{"jobType":"demo","payload":{"a":1},"idempotencyKey":"k","timeout":"1m","timeoutMs":60000}`,
+	}
+	for _, body := range cases {
+		s := newTestServer(&fakeStore{}, &fakeQueue{})
+		req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		assertAPIError(t, rec, http.StatusBadRequest, "invalid_timeout")
+	}
+}
+
+func TestSubmitJobPriority(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+	body := `{"jobType":"email","payload":{"to":"a@b.com"},"idempotencyKey":"k","priority":7}`
+	req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	if store.lastInsert.Priority != 7 {
+		t.Fatalf("expected priority 7 to be persisted, got %d", store.lastInsert.Priority)
+	}
+
+	for _, p := range []string{"-1", "10"} {
+		body := `{"jobType":"email","payload":{},"idempotencyKey":"k","priority":` + p + `}`
+		req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		assertAPIError(t, rec, http.StatusBadRequest, "invalid_priority")
+	}
+}
+
+func TestJobsListPriorityFilter(t *testing.T) {
+	store := fakeStore{}
+	s := newTestServer(&store, &fakeQueue{})
+	req := httptest.NewRequest(http.MethodGet, "/jobs?priority=9", nil)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if store.lastQuery.Priority == nil || *store.lastQuery.Priority != 9 {
+		t.Fatalf("expected priority filter 9, got %v", store.lastQuery.Priority)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/queues/jobs:ready/jobs?priority=high", nil)
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	assertAPIError(t, rec, http.StatusBadRequest, "invalid_priority")
+}
+
+func TestSubmitJobRoutesToRequestedQueue(t *testing.T) {
+	cfg := testConfig()
+	cfg.Queues = []string{"jobs:ready", "critical"}
+	store := fakeStore{}
+	q := &fakeQueue{}
+	s := NewServer(cfg, &store, q, nil, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+
+	body := `{"queue":"critical","jobType":"email","payload":{},"idempotencyKey":"k"}`
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	if len(q.queues) != 1 || q.queues[0] != "critical" {
+		t.Fatalf("expected enqueue to critical, got %v", q.queues)
+	}
+
+	body = `{"queue":"nope","jobType":"email","payload":{},"idempotencyKey":"k"}`
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewBufferString(body)))
+	assertAPIError(t, rec, http.StatusBadRequest, "unknown_queue")
+}
+
+func TestRetryAndReplayEnqueueToJobQueue(t *testing.T) {
+	store := fakeStore{retryOK: true, jobQueue: "bulk"}
+	q := &fakeQueue{}
+	s := newTestServer(&store, q)
+
+	for _, path := range []string{"/jobs/job-1/retry", "/dlq/job-1/replay"} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("%s: expected 204, got %d", path, rec.Code)
+		}
+	}
+	if len(q.queues) != 2 || q.queues[0] != "bulk" || q.queues[1] != "bulk" {
+		t.Fatalf("expected both enqueues to bulk, got %v", q.queues)
+	}
+}
+
 func TestJobsListOK(t *testing.T) {
 	store := fakeStore{
 		queryJobsResp:  []JobStatusResponse{{JobID: "job-1", JobType: "demo"}},
@@ -279,6 +509,423 @@ func TestGetJobNotFound(t *testing.T) {
 	assertAPIError(t, rec, http.StatusNotFound, "not_found")
 }
 
+func TestListJobAttempts(t *testing.T) {
+	finished := time.Date(2026, 2, 2, 12, 0, 1, 0, time.UTC)
+	duration := int64(1000)
+	store := fakeStore{attempts: map[string][]JobAttempt{
+		"job-1": {
+			{Attempt: 1, LeaseOwner: "worker-a", Outcome: "failed", StartedAt: finished.Add(-time.Second), FinishedAt: &finished, DurationMs: &duration, Error: "timeout", FailureClass: "retryable"},
+			{Attempt: 2, LeaseOwner: "worker-b", Outcome: "running", StartedAt: finished},
+		},
+	}}
+	s := newTestServer(&store, &fakeQueue{})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/attempts", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp JobAttemptsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.JobID != "job-1" || len(resp.Items) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Items[0].Error != "timeout" || resp.Items[0].FailureClass != "retryable" || *resp.Items[0].DurationMs != 1000 {
+		t.Fatalf("unexpected first attempt: %+v", resp.Items[0])
+	}
+	if resp.Items[1].FinishedAt != nil {
+		t.Fatalf("expected running attempt without finishedAt, got %+v", resp.Items[1])
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/missing/attempts", nil))
+	assertAPIError(t, rec, http.StatusNotFound, "not_found")
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs/job-1/attempts", nil))
+	assertAPIError(t, rec, http.StatusMethodNotAllowed, "invalid_method")
+}
+
+func TestListJobEvents(t *testing.T) {
+	at := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+	store := fakeStore{events: map[string][]JobEvent{
+		"job-1": {
+			{ID: 1, ToState: "PENDING", Actor: "api", Reason: "submitted", CreatedAt: at},
+			{ID: 2, FromState: "PENDING", ToState: "IN_PROGRESS", Actor: "worker:w-1", Reason: "leased", CreatedAt: at.Add(time.Second)},
+			{ID: 3, FromState: "IN_PROGRESS", ToState: "DLQ", Actor: "worker:w-1", Reason: "bad payload", CreatedAt: at.Add(2 * time.Second)},
+		},
+	}}
+	s := newTestServer(&store, &fakeQueue{})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/events", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp JobEventsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 3 || resp.Items[2].ToState != "DLQ" || resp.Items[2].Actor != "worker:w-1" {
+		t.Fatalf("unexpected events: %+v", resp.Items)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/missing/events", nil))
+	assertAPIError(t, rec, http.StatusNotFound, "not_found")
+}
+
+func TestGetJobResult(t *testing.T) {
+	store := fakeStore{results: map[string]JobResult{
+		"done":      {State: StateCompleted, Data: []byte(`{"sent":2}`), ContentType: "application/vnd.taskforge.receipt+json"},
+		"no-output": {State: StateCompleted},
+		"running":   {State: StateInProgress},
+	}}
+	s := newTestServer(&store, &fakeQueue{})
+	get := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+id+"/result", nil))
+		return rec
+	}
+
+	rec := get("done")
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"sent":2}` {
+		t.Fatalf("expected stored result, got %d %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/vnd.taskforge.receipt+json" {
+		t.Fatalf("expected stored content type, got %q", ct)
+	}
+	if rec := get("no-output"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for a completed job without result, got %d", rec.Code)
+	}
+	assertAPIError(t, get("running"), http.StatusConflict, "result_not_ready")
+	assertAPIError(t, get("missing"), http.StatusNotFound, "not_found")
+}
+
+func TestWaitJobWakesOnEvent(t *testing.T) {
+	store := &waitStore{fakeStore: &fakeStore{}, job: JobStatusResponse{JobID: "job-1", State: StateInProgress}}
+	hub := NewEventHub()
+	s := newTestServer(store, &fakeQueue{})
+	s.SetEventHub(hub)
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/wait?timeout=5s", nil))
+		done <- rec
+	}()
+
+	// Let the waiter register before the job changes.
+	for i := 0; ; i++ {
+		hub.mu.Lock()
+		n := len(hub.waiters["job-1"])
+		hub.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if i == 200 {
+			t.Fatal("waiter did not register")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	store.results = map[string]JobResult{"job-1": {State: StateCompleted, Data: []byte(`{"ok":true}`)}}
+	store.set(JobStatusResponse{JobID: "job-1", State: StateCompleted, HasResult: true})
+	hub.Publish(storage.JobEventNotice{ID: 7, JobID: "job-1", State: StateCompleted})
+
+	var rec *httptest.ResponseRecorder
+	select {
+	case rec = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the waiter to return after the event")
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp JobWaitResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Done || resp.Job.State != StateCompleted || string(resp.Result) != `{"ok":true}` || resp.ResultContentType != "application/json" {
+		t.Fatalf("unexpected wait response: %+v", resp)
+	}
+	if len(hub.waiters) != 0 {
+		t.Fatalf("expected the waiter to unregister, got %v", hub.waiters)
+	}
+}
+
+func TestWaitJobTimesOut(t *testing.T) {
+	store := &waitStore{fakeStore: &fakeStore{}, job: JobStatusResponse{JobID: "job-1", State: StatePending}}
+	s := newTestServer(store, &fakeQueue{})
+	s.SetEventHub(NewEventHub())
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/wait?timeout=20ms", nil))
+	var resp JobWaitResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusOK || resp.Done || resp.Job.State != StatePending {
+		t.Fatalf("expected a not-done 200 after the timeout, got %d %+v", rec.Code, resp)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/wait?timeout=soon", nil))
+	assertAPIError(t, rec, http.StatusBadRequest, "invalid_timeout")
+
+	missing := newTestServer(&fakeStore{getJobErr: errNotFound}, &fakeQueue{})
+	rec = httptest.NewRecorder()
+	missing.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/nope/wait", nil))
+	assertAPIError(t, rec, http.StatusNotFound, "not_found")
+}
+
+func TestWaitJobReturnsOnShutdown(t *testing.T) {
+	store := &waitStore{fakeStore: &fakeStore{}, job: JobStatusResponse{JobID: "job-1", State: StateInProgress}}
+	s := newTestServer(store, &fakeQueue{})
+	s.SetEventHub(NewEventHub())
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/job-1/wait?timeout=30s", nil))
+		done <- rec
+	}()
+	close(s.closing)
+
+	var rec *httptest.ResponseRecorder
+	select {
+	case rec = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the waiter to return on shutdown")
+	}
+	var resp JobWaitResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusOK || resp.Done || resp.Job.State != StateInProgress {
+		t.Fatalf("expected a not-done 200 with the current status, got %d %+v", rec.Code, resp)
+	}
+}
+
+// waitStore lets a test change the job GetJob returns while a waiter reads it.
+type waitStore struct {
+	*fakeStore
+	mu  sync.Mutex
+	job JobStatusResponse
+}
+
+func (s *waitStore) GetJob(ctx context.Context, jobID string) (JobStatusResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.job, nil
+}
+
+func (s *waitStore) set(job JobStatusResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.job = job
+}
+
+func TestStreamEventsResumesAndFilters(t *testing.T) {
+	store := fakeStore{eventLog: []JobStateEvent{
+		{ID: 1, JobID: "job-1", Queue: "critical", ToState: StatePending},
+		{ID: 2, JobID: "job-1", Queue: "critical", FromState: StatePending, ToState: StateInProgress},
+		{ID: 3, JobID: "job-2", Queue: "bulk", ToState: StatePending},
+	}}
+	hub := NewEventHub()
+	for _, ev := range store.eventLog {
+		hub.Publish(storage.JobEventNotice{ID: ev.ID, JobID: ev.JobID, Queue: ev.Queue, State: ev.ToState})
+	}
+	s := newTestServer(&store, &fakeQueue{})
+	s.SetEventHub(hub)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/events?queue=critical", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	events := make(chan JobStateEvent, 8)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var ev JobStateEvent
+				_ = json.Unmarshal([]byte(data), &ev)
+				events <- ev
+			}
+		}
+		close(events)
+	}()
+	next := func() JobStateEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected another event")
+			return JobStateEvent{}
+		}
+	}
+
+	if ev := next(); ev.ID != 2 {
+		t.Fatalf("expected replay of event 2, got %+v", ev)
+	}
+	// The stream subscribed before flushing the replay, so these reach it.
+	hub.Publish(storage.JobEventNotice{ID: 4, JobID: "job-2", Queue: "bulk", State: StateInProgress})
+	hub.Publish(storage.JobEventNotice{ID: 5, JobID: "job-1", Queue: "critical", FromState: StateInProgress, State: StateCompleted})
+	if ev := next(); ev.ID != 5 || ev.ToState != StateCompleted || ev.FromState != StateInProgress {
+		t.Fatalf("expected live event 5 only, got %+v", ev)
+	}
+}
+
+func TestStreamEventsDeliversLateLowerIDAfterReplay(t *testing.T) {
+	// Event 3 was still uncommitted when the replay read the log.
+	store := fakeStore{eventLog: []JobStateEvent{
+		{ID: 2, JobID: "job-1", ToState: StatePending},
+		{ID: 4, JobID: "job-2", ToState: StatePending},
+	}}
+	hub := NewEventHub()
+	hub.Publish(storage.JobEventNotice{ID: 2, JobID: "job-1", State: StatePending})
+	hub.Publish(storage.JobEventNotice{ID: 4, JobID: "job-2", State: StatePending})
+	s := newTestServer(&store, &fakeQueue{})
+	s.SetEventHub(hub)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	ids := make(chan string, 8)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+				ids <- id
+			}
+		}
+		close(ids)
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case id := <-ids:
+			return id
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected another event")
+			return ""
+		}
+	}
+
+	if a, b := next(), next(); a != "2" || b != "4" {
+		t.Fatalf("expected replay of 2 and 4, got %s and %s", a, b)
+	}
+	hub.Publish(storage.JobEventNotice{ID: 3, JobID: "job-3", State: StatePending})
+	hub.Publish(storage.JobEventNotice{ID: 5, JobID: "job-1", State: StateInProgress})
+	if a, b := next(), next(); a != "3" || b != "5" {
+		t.Fatalf("expected late event 3 then 5, got %s and %s", a, b)
+	}
+}
+
+func TestStreamEventsCatchesUpWithoutDuplicates(t *testing.T) {
+	// A backlog longer than the subscriber buffer, and an event committed
+	// while it is replayed whose notice arrives after the stream subscribed.
+	var backlog []JobStateEvent
+	for id := int64(2); id <= 2*streamBuffer; id++ {
+		backlog = append(backlog, JobStateEvent{ID: id, JobID: "job-1", ToState: StatePending})
+	}
+	store := &growingEventStore{
+		fakeStore: &fakeStore{eventLog: backlog},
+		late:      JobStateEvent{ID: 2*streamBuffer + 1, JobID: "job-2", ToState: StatePending},
+	}
+	hub := NewEventHub()
+	hub.Publish(storage.JobEventNotice{ID: 2 * streamBuffer, JobID: "job-1", State: StatePending})
+	s := newTestServer(store, &fakeQueue{})
+	s.SetEventHub(hub)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	next := func() string {
+		t.Helper()
+		for scanner.Scan() {
+			if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+				return id
+			}
+		}
+		t.Fatal("stream ended early")
+		return ""
+	}
+	for id := 2; id <= 2*streamBuffer+1; id++ {
+		if got := next(); got != strconv.Itoa(id) {
+			t.Fatalf("expected replayed event %d, got %s", id, got)
+		}
+	}
+	hub.Publish(storage.JobEventNotice{ID: 2*streamBuffer + 1, JobID: "job-2", State: StatePending})
+	hub.Publish(storage.JobEventNotice{ID: 2*streamBuffer + 2, JobID: "job-2", State: StateInProgress})
+	if got := next(); got != strconv.Itoa(2*streamBuffer+2) {
+		t.Fatalf("expected the caught-up event once and then live event %d, got %s", 2*streamBuffer+2, got)
+	}
+}
+
+// growingEventStore commits late to the event log once the log has been read
+// to its end, as if it had been written while a stream replayed it.
+type growingEventStore struct {
+	*fakeStore
+	late JobStateEvent
+}
+
+func (s *growingEventStore) ListEventsSince(ctx context.Context, afterID int64, filter EventFilter, limit int) ([]JobStateEvent, error) {
+	items, _ := s.fakeStore.ListEventsSince(ctx, afterID, filter, limit)
+	if len(items) < limit && s.late.ID != 0 {
+		s.eventLog = append(s.eventLog, s.late)
+		s.late = JobStateEvent{}
+	}
+	return items, nil
+}
+
+func TestStreamEventsDropsSlowSubscriber(t *testing.T) {
+	hub := NewEventHub()
+	st := hub.subscribe(EventFilter{})
+	for i := 0; i <= streamBuffer; i++ {
+		hub.Publish(storage.JobEventNotice{ID: int64(i + 1), JobID: "job-1", State: StatePending})
+	}
+	n := 0
+	for range st.ch {
+		n++
+	}
+	if n != streamBuffer {
+		t.Fatalf("expected %d buffered events before the drop, got %d", streamBuffer, n)
+	}
+	hub.unsubscribe(st)
+
+	s := newTestServer(&fakeStore{}, &fakeQueue{})
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assertAPIError(t, rec, http.StatusServiceUnavailable, "events_unavailable")
+}
+
 func TestStatsOK(t *testing.T) {
 	store := fakeStore{statsCounts: StatsCounts{Total: 2, Pending: 1, Failed: 1, DLQ: 0}}
 	q := &fakeQueue{}
@@ -332,10 +979,50 @@ func assertAPIError(t *testing.T, rec *httptest.ResponseRecorder, status int, co
 var errTest = errors.New("test error")
 var errUnique = errors.New("duplicate key value violates unique constraint")
 
+func TestQueueDepthCollectorCoversEveryQueue(t *testing.T) {
+	store := depthStore{fakeStore: &fakeStore{statsCounts: StatsCounts{DLQ: 2}}, depths: map[string]int64{"jobs:ready": 3, "critical": 1}}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.NewQueueDLQCollector([]string{"jobs:ready", "critical", "bulk"}, queueDLQProvider{store: store}))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	depths := map[string]float64{}
+	var dlq float64
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			switch mf.GetName() {
+			case "taskforge_queue_depth":
+				depths[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+			case "taskforge_dlq_count":
+				dlq = m.GetGauge().GetValue()
+			}
+		}
+	}
+	if len(depths) != 3 || depths["jobs:ready"] != 3 || depths["critical"] != 1 || depths["bulk"] != 0 {
+		t.Fatalf("expected a depth for every queue, got %v", depths)
+	}
+	if dlq != 2 {
+		t.Fatalf("expected dlq count 2, got %v", dlq)
+	}
+}
+
+// depthStore reports a separate queue depth per queue.
+type depthStore struct {
+	*fakeStore
+	depths map[string]int64
+}
+
+func (s depthStore) QueueDepth(ctx context.Context, queueName string) (int64, error) {
+	return s.depths[queueName], nil
+}
+
 type fakeStore struct {
 	pingErr         error
 	insertErr       error
 	insertCount     int
+	lastInsert      SubmitJobRequest
 	idemSeen        map[string]string
 	getJobResp      JobStatusResponse
 	getJobErr       error
@@ -352,6 +1039,9 @@ type fakeStore struct {
 	dlqErr          error
 	dlqCalled       bool
 	dlqReason       string
+	running         bool
+	cancelReason    string
+	cancelErr       error
 	statsCounts     StatsCounts
 	statsErr        error
 	dlqEntries      []DLQEntry
@@ -359,6 +1049,13 @@ type fakeStore struct {
 	getDLQEntryResp DLQEntry
 	getDLQEntryErr  error
 	replayErr       error
+	jobQueue        string
+	published       []string
+	attempts        map[string][]JobAttempt
+	events          map[string][]JobEvent
+	eventLog        []JobStateEvent
+	results         map[string]JobResult
+	schedules       map[string]ScheduleResponse
 }
 
 func (f fakeStore) Ping(ctx context.Context) error {
@@ -367,6 +1064,7 @@ func (f fakeStore) Ping(ctx context.Context) error {
 
 func (f *fakeStore) InsertJob(ctx context.Context, jobID string, req SubmitJobRequest, traceparent string, queueName string) error {
 	f.insertCount++
+	f.lastInsert = req
 	if f.idemSeen != nil {
 		if existingID, ok := f.idemSeen[queueName+"|"+req.IdempotencyKey]; ok {
 			f.getByKeyResp = JobStatusResponse{JobID: existingID}
@@ -404,11 +1102,11 @@ func (f *fakeStore) QueryJobs(ctx context.Context, q JobsQuery) ([]JobStatusResp
 	return f.queryJobsResp, f.queryJobsTotal, nil
 }
 
-func (f fakeStore) RetryJob(ctx context.Context, jobID string) (bool, error) {
+func (f fakeStore) RetryJob(ctx context.Context, jobID string) (string, bool, error) {
 	if f.retryErr != nil {
-		return false, f.retryErr
+		return "", false, f.retryErr
 	}
-	return f.retryOK, nil
+	return f.jobQueue, f.retryOK, nil
 }
 
 func (f *fakeStore) DLQJob(ctx context.Context, jobID string, reason string) (bool, error) {
@@ -420,6 +1118,17 @@ func (f *fakeStore) DLQJob(ctx context.Context, jobID string, reason string) (bo
 	return f.dlqOK, nil
 }
 
+func (f *fakeStore) CancelJob(ctx context.Context, jobID string, reason string) (string, error) {
+	if f.cancelErr != nil {
+		return "", f.cancelErr
+	}
+	f.cancelReason = reason
+	if f.running {
+		return StateInProgress, nil
+	}
+	return StateCanceled, nil
+}
+
 func (f fakeStore) Stats(ctx context.Context) (StatsCounts, error) {
 	if f.statsErr != nil {
 		return StatsCounts{}, f.statsErr
@@ -427,10 +1136,53 @@ func (f fakeStore) Stats(ctx context.Context) (StatsCounts, error) {
 	return f.statsCounts, nil
 }
 
+func (f fakeStore) ListJobAttempts(ctx context.Context, jobID string) ([]JobAttempt, error) {
+	attempts, ok := f.attempts[jobID]
+	if !ok {
+		return nil, errNotFound
+	}
+	return attempts, nil
+}
+
+func (f fakeStore) GetJobResult(ctx context.Context, jobID string) (JobResult, error) {
+	res, ok := f.results[jobID]
+	if !ok {
+		return JobResult{}, errNotFound
+	}
+	return res, nil
+}
+
+func (f fakeStore) ListJobEvents(ctx context.Context, jobID string) ([]JobEvent, error) {
+	events, ok := f.events[jobID]
+	if !ok {
+		return nil, errNotFound
+	}
+	return events, nil
+}
+
+func (f fakeStore) ListEventsSince(ctx context.Context, afterID int64, filter EventFilter, limit int) ([]JobStateEvent, error) {
+	var out []JobStateEvent
+	for _, ev := range f.eventLog {
+		if ev.ID > afterID && filter.matches(ev) && len(out) < limit {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) MarkJobPublished(ctx context.Context, jobID string) {
+	f.published = append(f.published, jobID)
+}
+
+func (f fakeStore) QueueDepth(ctx context.Context, queueName string) (int64, error) {
+	return int64(f.statsCounts.Pending), nil
+}
+
 type fakeQueue struct {
 	pingErr    error
 	enqueueErr error
 	enqueued   []string
+	queues     []string
 }
 
 func (f *fakeQueue) Ping(ctx context.Context) error {
@@ -439,9 +1191,6 @@ func (f *fakeQueue) Ping(ctx context.Context) error {
 
 func (f *fakeQueue) Enqueue(ctx context.Context, queueName string, jobID string) error {
 	f.enqueued = append(f.enqueued, jobID)
+	f.queues = append(f.queues, queueName)
 	return f.enqueueErr
 }
-
-func (f *fakeQueue) QueueDepth(ctx context.Context, queueName string) (int64, error) {
-	return int64(len(f.enqueued)), nil
-}
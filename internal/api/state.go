@@ -7,5 +7,6 @@ const (
 	StateFailed     = "FAILED"
 	StateRetrying   = "RETRYING"
 	StateDLQ        = "DLQ"
+	StateCanceled   = "CANCELED"
 	StateDead       = "DEAD"
 )
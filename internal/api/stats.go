@@ -7,19 +7,21 @@ import (
 )
 
 type StatsPoint struct {
-	Ts      string `json:"ts"`
-	Total   int    `json:"total"`
-	Pending int    `json:"pending"`
-	Failed  int    `json:"failed"`
-	DLQ     int    `json:"dlq"`
+	Ts       string `json:"ts"`
+	Total    int    `json:"total"`
+	Pending  int    `json:"pending"`
+	Failed   int    `json:"failed"`
+	DLQ      int    `json:"dlq"`
+	Canceled int    `json:"canceled"`
 }
 
 type StatsResponse struct {
-	Total   int          `json:"total"`
-	Pending int          `json:"pending"`
-	Failed  int          `json:"failed"`
-	DLQ     int          `json:"dlq"`
-	Points  []StatsPoint `json:"points"`
+	Total    int          `json:"total"`
+	Pending  int          `json:"pending"`
+	Failed   int          `json:"failed"`
+	DLQ      int          `json:"dlq"`
+	Canceled int          `json:"canceled"`
+	Points   []StatsPoint `json:"points"`
 }
 
 func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
@@ -39,12 +41,13 @@ func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
 
 	now := time.Now().UTC().Format(time.RFC3339)
 	resp := StatsResponse{
-		Total:   counts.Total,
-		Pending: counts.Pending,
-		Failed:  counts.Failed,
-		DLQ:     counts.DLQ,
+		Total:    counts.Total,
+		Pending:  counts.Pending,
+		Failed:   counts.Failed,
+		DLQ:      counts.DLQ,
+		Canceled: counts.Canceled,
 		Points: []StatsPoint{
-			{Ts: now, Total: counts.Total, Pending: counts.Pending, Failed: counts.Failed, DLQ: counts.DLQ},
+			{Ts: now, Total: counts.Total, Pending: counts.Pending, Failed: counts.Failed, DLQ: counts.DLQ, Canceled: counts.Canceled},
 		},
 	}
 
@@ -10,17 +10,46 @@ type Store interface {
 	InsertDLQEntry(ctx context.Context, jobID string, reason string) error
 	ListDLQ(ctx context.Context, limit, offset int) ([]DLQEntry, int, error)
 	GetDLQEntry(ctx context.Context, jobID string) (DLQEntry, error)
-	ReplayDLQ(ctx context.Context, jobID string) error
+	// ReplayDLQ and RetryJob return the job's queue so callers can re-enqueue it there.
+	ReplayDLQ(ctx context.Context, jobID string) (string, error)
 	GetTraceparent(ctx context.Context, jobID string) (string, error)
 	QueryJobs(ctx context.Context, q JobsQuery) ([]JobStatusResponse, int, error)
-	RetryJob(ctx context.Context, jobID string) (bool, error)
+	// ListJobAttempts returns the job's attempts oldest first, or errNotFound
+	// if the job does not exist.
+	ListJobAttempts(ctx context.Context, jobID string) ([]JobAttempt, error)
+	// GetJobResult returns errNotFound if the job does not exist.
+	GetJobResult(ctx context.Context, jobID string) (JobResult, error)
+	// ListJobEvents returns the job's state changes oldest first, or
+	// errNotFound if the job does not exist.
+	ListJobEvents(ctx context.Context, jobID string) ([]JobEvent, error)
+	// ListEventsSince returns up to limit events matching f with an ID above
+	// afterID, oldest first. GET /events uses it to resume a stream.
+	ListEventsSince(ctx context.Context, afterID int64, f EventFilter, limit int) ([]JobStateEvent, error)
+	RetryJob(ctx context.Context, jobID string) (string, bool, error)
+	// MarkJobPublished records that the job's outbox entry was published
+	// directly, so the outbox relay skips it. It is best effort.
+	MarkJobPublished(ctx context.Context, jobID string)
 	DLQJob(ctx context.Context, jobID string, reason string) (bool, error)
+	// CancelJob cancels a PENDING, RETRYING or FAILED job outright and
+	// returns StateCanceled. An IN_PROGRESS job is only flagged and
+	// StateInProgress returned; the worker running it cancels the handler on
+	// its next heartbeat and moves the job to CANCELED. Other states yield
+	// errInvalidTransition.
+	CancelJob(ctx context.Context, jobID string, reason string) (string, error)
 	Stats(ctx context.Context) (StatsCounts, error)
+	// QueueDepth counts PENDING jobs in queueName that are ready to lease.
+	QueueDepth(ctx context.Context, queueName string) (int64, error)
+	CreateSchedule(ctx context.Context, sched ScheduleResponse) (ScheduleResponse, error)
+	GetSchedule(ctx context.Context, scheduleID string) (ScheduleResponse, error)
+	ListSchedules(ctx context.Context, limit, offset int) ([]ScheduleResponse, int, error)
+	UpdateSchedule(ctx context.Context, sched ScheduleResponse) (ScheduleResponse, error)
+	DeleteSchedule(ctx context.Context, scheduleID string) error
 }
 
 type StatsCounts struct {
-	Total   int
-	Pending int
-	Failed  int
-	DLQ     int
+	Total    int
+	Pending  int
+	Failed   int
+	DLQ      int
+	Canceled int
 }
@@ -7,7 +7,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/pranavko12/taskforge/internal/storage"
 )
 
 var errNotFound = errors.New("not found")
@@ -21,33 +25,19 @@ func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
 	return &PostgresStore{pool: pool}
 }
 
-func (s *PostgresStore) Ping(ctx context.Context) error {
-	return s.pool.Ping(ctx)
-}
-
-func (s *PostgresStore) InsertJob(ctx context.Context, jobID string, req SubmitJobRequest, traceparent string, queueName string) error {
-	_, err := s.pool.Exec(ctx, `
-		INSERT INTO jobs (
-			job_id, queue_name, job_type, payload, idempotency_key, state, max_retries,
-			max_attempts, attempt_count, initial_delay, backoff, max_delay, jitter, next_run_at, traceparent
-		)
-		VALUES ($1, $2, $3, $4, $5, 'PENDING', $6, $7, 0, $8, $9, $10, $11, NOW(), $12)
-	`, jobID, queueName, req.JobType, req.Payload, req.IdempotencyKey, req.MaxRetries, req.MaxAttempts, req.InitialDelay, req.Backoff, req.MaxDelay, req.Jitter, traceparent)
-	return err
-}
+const jobColumns = `job_id, queue_name, job_type, state, priority, retry_count, max_retries, max_attempts, attempt_count,
+			initial_delay, backoff, max_delay, jitter, timeout_ms, next_run_at, traceparent,
+			COALESCE(last_error, ''), scheduled_at, available_at, started_at, completed_at, created_at, updated_at,
+			last_response_status, last_response_excerpt, result IS NOT NULL`
 
-func (s *PostgresStore) GetJob(ctx context.Context, jobID string) (JobStatusResponse, error) {
+func scanJob(row pgx.Row) (JobStatusResponse, error) {
 	var resp JobStatusResponse
-	err := s.pool.QueryRow(ctx, `
-		SELECT job_id, job_type, state, retry_count, max_retries, max_attempts, attempt_count,
-			initial_delay, backoff, max_delay, jitter, next_run_at, traceparent,
-			COALESCE(last_error, ''), scheduled_at, available_at, started_at, completed_at, created_at, updated_at
-		FROM jobs
-		WHERE job_id = $1
-	`, jobID).Scan(
+	err := row.Scan(
 		&resp.JobID,
+		&resp.Queue,
 		&resp.JobType,
 		&resp.State,
+		&resp.Priority,
 		&resp.RetryCount,
 		&resp.MaxRetries,
 		&resp.MaxAttempts,
@@ -56,6 +46,7 @@ func (s *PostgresStore) GetJob(ctx context.Context, jobID string) (JobStatusResp
 		&resp.Backoff,
 		&resp.MaxDelay,
 		&resp.Jitter,
+		&resp.TimeoutMs,
 		&resp.NextRunAt,
 		&resp.Traceparent,
 		&resp.LastError,
@@ -65,7 +56,65 @@ func (s *PostgresStore) GetJob(ctx context.Context, jobID string) (JobStatusResp
 		&resp.CompletedAt,
 		&resp.CreatedAt,
 		&resp.UpdatedAt,
+		&resp.LastResponseStatus,
+		&resp.LastResponseExcerpt,
+		&resp.HasResult,
 	)
+	return resp, err
+}
+
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+func (s *PostgresStore) InsertJob(ctx context.Context, jobID string, req SubmitJobRequest, traceparent string, queueName string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorAPI, "submitted"); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO jobs (
+			job_id, queue_name, job_type, payload, idempotency_key, state, max_retries,
+			max_attempts, attempt_count, initial_delay, backoff, max_delay, jitter, traceparent,
+			scheduled_at, available_at, next_run_at, priority, timeout_ms
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, 'PENDING', $6, $7, 0, $8, $9, $10, $11, $12,
+			COALESCE($13, NOW()), COALESCE($13, NOW()), COALESCE($13, NOW()), $14, $15
+		)
+	`, jobID, queueName, req.JobType, req.Payload, req.IdempotencyKey, req.MaxRetries, req.MaxAttempts, req.InitialDelay, req.Backoff, req.MaxDelay, req.Jitter, traceparent, req.RunAt, req.Priority, req.TimeoutMs)
+	if err != nil {
+		return err
+	}
+	if err = storage.InsertOutbox(ctx, tx, jobID, queueName); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	storage.Notify(ctx, s.pool, queueName)
+	return nil
+}
+
+func (s *PostgresStore) MarkJobPublished(ctx context.Context, jobID string) {
+	storage.MarkOutboxPublished(ctx, s.pool, jobID)
+}
+
+func (s *PostgresStore) GetJob(ctx context.Context, jobID string) (JobStatusResponse, error) {
+	resp, err := scanJob(s.pool.QueryRow(ctx, `
+		SELECT `+jobColumns+`
+		FROM jobs
+		WHERE job_id = $1
+	`, jobID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return JobStatusResponse{}, errNotFound
@@ -75,38 +124,148 @@ func (s *PostgresStore) GetJob(ctx context.Context, jobID string) (JobStatusResp
 	return resp, nil
 }
 
-func (s *PostgresStore) GetJobByIdempotencyKey(ctx context.Context, key string, queueName string) (JobStatusResponse, error) {
-	var resp JobStatusResponse
+func (s *PostgresStore) ListJobAttempts(ctx context.Context, jobID string) ([]JobAttempt, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT attempt, lease_owner, lease_token, outcome, started_at, finished_at, duration_ms, error, failure_class
+		FROM job_attempts
+		WHERE job_id = $1
+		ORDER BY attempt_id ASC
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []JobAttempt{}
+	for rows.Next() {
+		var a JobAttempt
+		if err := rows.Scan(
+			&a.Attempt,
+			&a.LeaseOwner,
+			&a.LeaseToken,
+			&a.Outcome,
+			&a.StartedAt,
+			&a.FinishedAt,
+			&a.DurationMs,
+			&a.Error,
+			&a.FailureClass,
+		); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(attempts) == 0 {
+		if err := s.checkJobExists(ctx, jobID); err != nil {
+			return nil, err
+		}
+	}
+	return attempts, nil
+}
+
+func (s *PostgresStore) GetJobResult(ctx context.Context, jobID string) (JobResult, error) {
+	var res JobResult
 	err := s.pool.QueryRow(ctx, `
-		SELECT job_id, job_type, state, retry_count, max_retries, max_attempts, attempt_count,
-			initial_delay, backoff, max_delay, jitter, next_run_at, traceparent,
-			COALESCE(last_error, ''), scheduled_at, available_at, started_at, completed_at, created_at, updated_at
+		SELECT state::text, result::text, COALESCE(result_content_type, '')
+		FROM jobs
+		WHERE job_id = $1
+	`, jobID).Scan(&res.State, &res.Data, &res.ContentType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return JobResult{}, errNotFound
+		}
+		return JobResult{}, err
+	}
+	return res, nil
+}
+
+func (s *PostgresStore) ListJobEvents(ctx context.Context, jobID string) ([]JobEvent, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT event_id, COALESCE(from_state::text, ''), to_state::text, actor, reason, created_at
+		FROM job_events
+		WHERE job_id = $1
+		ORDER BY event_id ASC
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []JobEvent{}
+	for rows.Next() {
+		var e JobEvent
+		if err := rows.Scan(&e.ID, &e.FromState, &e.ToState, &e.Actor, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		if err := s.checkJobExists(ctx, jobID); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+func (s *PostgresStore) ListEventsSince(ctx context.Context, afterID int64, f EventFilter, limit int) ([]JobStateEvent, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT e.event_id, e.job_id::text, j.queue_name, j.job_type,
+			COALESCE(e.from_state::text, ''), e.to_state::text, e.actor, e.created_at
+		FROM job_events e
+		JOIN jobs j ON j.job_id = e.job_id
+		WHERE e.event_id > $1
+			AND (COALESCE(cardinality($2::text[]), 0) = 0 OR j.queue_name = ANY($2))
+			AND (COALESCE(cardinality($3::text[]), 0) = 0 OR j.job_type = ANY($3))
+			AND (COALESCE(cardinality($4::text[]), 0) = 0 OR e.to_state::text = ANY($4))
+			AND (COALESCE(cardinality($5::text[]), 0) = 0 OR e.job_id::text = ANY($5))
+		ORDER BY e.event_id ASC
+		LIMIT $6
+	`, afterID, f.Queues, f.JobTypes, f.States, f.JobIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []JobStateEvent{}
+	for rows.Next() {
+		var e JobStateEvent
+		if err := rows.Scan(&e.ID, &e.JobID, &e.Queue, &e.JobType, &e.FromState, &e.ToState, &e.Actor, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// checkJobExists returns errNotFound if jobID does not exist, so an empty
+// history can be told apart from an unknown job.
+func (s *PostgresStore) checkJobExists(ctx context.Context, jobID string) error {
+	var exists bool
+	if err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM jobs WHERE job_id = $1)`, jobID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) GetJobByIdempotencyKey(ctx context.Context, key string, queueName string) (JobStatusResponse, error) {
+	resp, err := scanJob(s.pool.QueryRow(ctx, `
+		SELECT `+jobColumns+`
 		FROM jobs
 		WHERE idempotency_key = $1 AND queue_name = $2
 		ORDER BY created_at DESC
 		LIMIT 1
-	`, key, queueName).Scan(
-		&resp.JobID,
-		&resp.JobType,
-		&resp.State,
-		&resp.RetryCount,
-		&resp.MaxRetries,
-		&resp.MaxAttempts,
-		&resp.AttemptCount,
-		&resp.InitialDelay,
-		&resp.Backoff,
-		&resp.MaxDelay,
-		&resp.Jitter,
-		&resp.NextRunAt,
-		&resp.Traceparent,
-		&resp.LastError,
-		&resp.ScheduledAt,
-		&resp.AvailableAt,
-		&resp.StartedAt,
-		&resp.CompletedAt,
-		&resp.CreatedAt,
-		&resp.UpdatedAt,
-	)
+	`, key, queueName))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return JobStatusResponse{}, errNotFound
@@ -117,7 +276,16 @@ func (s *PostgresStore) GetJobByIdempotencyKey(ctx context.Context, key string,
 }
 
 func (s *PostgresStore) InsertDLQEntry(ctx context.Context, jobID string, reason string) error {
-	tag, err := s.pool.Exec(ctx, `
+	return insertDLQEntry(ctx, s.pool, jobID, reason)
+}
+
+// execer is satisfied by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func insertDLQEntry(ctx context.Context, db execer, jobID string, reason string) error {
+	tag, err := db.Exec(ctx, `
 		INSERT INTO dead_letters (
 			job_id, queue_name, job_type, payload, reason, last_error, attempts, failed_at, created_at, updated_at
 		)
@@ -206,10 +374,10 @@ func (s *PostgresStore) GetDLQEntry(ctx context.Context, jobID string) (DLQEntry
 	return entry, nil
 }
 
-func (s *PostgresStore) ReplayDLQ(ctx context.Context, jobID string) error {
+func (s *PostgresStore) ReplayDLQ(ctx context.Context, jobID string) (string, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer func() {
 		if err != nil {
@@ -217,11 +385,16 @@ func (s *PostgresStore) ReplayDLQ(ctx context.Context, jobID string) error {
 		}
 	}()
 
-	tag, err := tx.Exec(ctx, `
+	if err = storage.SetEventContext(ctx, tx, storage.ActorAPI, "dlq replay"); err != nil {
+		return "", err
+	}
+	var queueName string
+	err = tx.QueryRow(ctx, `
 		UPDATE jobs
 		SET state = 'PENDING',
 			retry_count = 0,
 			attempt_count = 0,
+			lease_expirations = 0,
 			lease_owner = NULL,
 			lease_expires_at = NULL,
 			last_error = '',
@@ -231,23 +404,28 @@ func (s *PostgresStore) ReplayDLQ(ctx context.Context, jobID string) error {
 			available_at = NOW(),
 			updated_at = NOW()
 		WHERE job_id = $1
-	`, jobID)
+		RETURNING queue_name
+	`, jobID).Scan(&queueName)
 	if err != nil {
-		return err
-	}
-	if tag.RowsAffected() == 0 {
-		return errNotFound
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errNotFound
+		}
+		return "", err
 	}
 
 	_, err = tx.Exec(ctx, `DELETE FROM dead_letters WHERE job_id = $1`, jobID)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if err = storage.InsertOutbox(ctx, tx, jobID, queueName); err != nil {
+		return "", err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	storage.Notify(ctx, s.pool, queueName)
+	return queueName, nil
 }
 
 func (s *PostgresStore) GetTraceparent(ctx context.Context, jobID string) (string, error) {
@@ -286,6 +464,10 @@ func (s *PostgresStore) QueryJobs(ctx context.Context, q JobsQuery) ([]JobStatus
 		p := addArg(q.JobType)
 		whereParts = append(whereParts, "job_type = "+p)
 	}
+	if q.Priority != nil {
+		p := addArg(*q.Priority)
+		whereParts = append(whereParts, "priority = "+p)
+	}
 	if q.Q != "" {
 		p := addArg("%" + q.Q + "%")
 		whereParts = append(whereParts, "(job_id ILIKE "+p+" OR idempotency_key ILIKE "+p+")")
@@ -300,9 +482,7 @@ func (s *PostgresStore) QueryJobs(ctx context.Context, q JobsQuery) ([]JobStatus
 	}
 
 	itemsSQL := `
-		SELECT job_id, job_type, state, retry_count, max_retries, max_attempts, attempt_count,
-			initial_delay, backoff, max_delay, jitter, next_run_at, traceparent,
-			COALESCE(last_error, ''), scheduled_at, available_at, started_at, completed_at, created_at, updated_at
+		SELECT ` + jobColumns + `
 		FROM jobs
 		WHERE ` + where + `
 		ORDER BY created_at DESC
@@ -317,29 +497,8 @@ func (s *PostgresStore) QueryJobs(ctx context.Context, q JobsQuery) ([]JobStatus
 
 	var items []JobStatusResponse
 	for rows.Next() {
-		var resp JobStatusResponse
-		if err := rows.Scan(
-			&resp.JobID,
-			&resp.JobType,
-			&resp.State,
-			&resp.RetryCount,
-			&resp.MaxRetries,
-			&resp.MaxAttempts,
-			&resp.AttemptCount,
-			&resp.InitialDelay,
-			&resp.Backoff,
-			&resp.MaxDelay,
-			&resp.Jitter,
-			&resp.NextRunAt,
-			&resp.Traceparent,
-			&resp.LastError,
-			&resp.ScheduledAt,
-			&resp.AvailableAt,
-			&resp.StartedAt,
-			&resp.CompletedAt,
-			&resp.CreatedAt,
-			&resp.UpdatedAt,
-		); err != nil {
+		resp, err := scanJob(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		items = append(items, resp)
@@ -351,26 +510,50 @@ func (s *PostgresStore) QueryJobs(ctx context.Context, q JobsQuery) ([]JobStatus
 	return items, total, nil
 }
 
-func (s *PostgresStore) RetryJob(ctx context.Context, jobID string) (bool, error) {
-	var state string
-	if err := s.pool.QueryRow(ctx, `SELECT state FROM jobs WHERE job_id = $1`, jobID).Scan(&state); err != nil {
+func (s *PostgresStore) RetryJob(ctx context.Context, jobID string) (string, bool, error) {
+	var state, queueName string
+	if err := s.pool.QueryRow(ctx, `SELECT state, queue_name FROM jobs WHERE job_id = $1`, jobID).Scan(&state, &queueName); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return false, errNotFound
+			return "", false, errNotFound
 		}
-		return false, err
+		return "", false, err
 	}
 	if state != StateFailed && state != StateRetrying && state != StateDLQ && state != StateDead {
-		return false, errInvalidTransition
+		return "", false, errInvalidTransition
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorAPI, "manual retry"); err != nil {
+		return "", false, err
 	}
-	tag, err := s.pool.Exec(ctx, `
+	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET state = $1, last_error = '', updated_at = NOW()
 		WHERE job_id = $2
 	`, StatePending, jobID)
 	if err != nil {
-		return false, err
+		return "", false, err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return queueName, false, nil
 	}
-	return tag.RowsAffected() > 0, nil
+	if err = storage.InsertOutbox(ctx, tx, jobID, queueName); err != nil {
+		return "", false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return "", false, err
+	}
+	storage.Notify(ctx, s.pool, queueName)
+	return queueName, true, nil
 }
 
 func (s *PostgresStore) DLQJob(ctx context.Context, jobID string, reason string) (bool, error) {
@@ -384,7 +567,24 @@ func (s *PostgresStore) DLQJob(ctx context.Context, jobID string, reason string)
 	if state != StateFailed && state != StateRetrying && state != StateInProgress {
 		return false, errInvalidTransition
 	}
-	tag, err := s.pool.Exec(ctx, `
+	if reason == "" {
+		reason = "manual dlq"
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorAPI, reason); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET state = $1, updated_at = NOW()
 		WHERE job_id = $2
@@ -392,15 +592,69 @@ func (s *PostgresStore) DLQJob(ctx context.Context, jobID string, reason string)
 	if err != nil {
 		return false, err
 	}
-	if tag.RowsAffected() > 0 {
-		if reason == "" {
-			reason = "manual dlq"
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return false, nil
+	}
+	if err = insertDLQEntry(ctx, tx, jobID, reason); err != nil {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *PostgresStore) CancelJob(ctx context.Context, jobID string, reason string) (state string, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = tx.QueryRow(ctx, `SELECT state FROM jobs WHERE job_id = $1 FOR UPDATE`, jobID).Scan(&state); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errNotFound
 		}
-		if err := s.InsertDLQEntry(ctx, jobID, reason); err != nil {
-			return false, err
+		return "", err
+	}
+
+	switch state {
+	case StateInProgress:
+		_, err = tx.Exec(ctx, `
+			UPDATE jobs
+			SET cancel_requested_at = COALESCE(cancel_requested_at, NOW()),
+				cancel_reason = $2,
+				updated_at = NOW()
+			WHERE job_id = $1
+		`, jobID, reason)
+	case StatePending, StateRetrying, StateFailed:
+		if err = storage.SetEventContext(ctx, tx, storage.ActorAPI, reason); err != nil {
+			return "", err
 		}
+		state = StateCanceled
+		_, err = tx.Exec(ctx, `
+			UPDATE jobs
+			SET state = 'CANCELED',
+				cancel_reason = $2,
+				last_error = $2,
+				updated_at = NOW()
+			WHERE job_id = $1
+		`, jobID, reason)
+	default:
+		err = errInvalidTransition
+	}
+	if err != nil {
+		return "", err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
 	}
-	return tag.RowsAffected() > 0, nil
+	return state, nil
 }
 
 func (s *PostgresStore) Stats(ctx context.Context) (StatsCounts, error) {
@@ -410,11 +664,151 @@ func (s *PostgresStore) Stats(ctx context.Context) (StatsCounts, error) {
 			COUNT(1),
 			COUNT(1) FILTER (WHERE state = 'PENDING'),
 			COUNT(1) FILTER (WHERE state = 'FAILED'),
-			COUNT(1) FILTER (WHERE state = 'DLQ')
+			COUNT(1) FILTER (WHERE state = 'DLQ'),
+			COUNT(1) FILTER (WHERE state = 'CANCELED')
 		FROM jobs
-	`).Scan(&counts.Total, &counts.Pending, &counts.Failed, &counts.DLQ)
+	`).Scan(&counts.Total, &counts.Pending, &counts.Failed, &counts.DLQ, &counts.Canceled)
 	if err != nil {
 		return StatsCounts{}, err
 	}
 	return counts, nil
 }
+
+func (s *PostgresStore) QueueDepth(ctx context.Context, queueName string) (int64, error) {
+	var depth int64
+	err := s.pool.QueryRow(ctx, `
+		SELECT COUNT(1)
+		FROM jobs
+		WHERE queue_name = $1
+			AND state = 'PENDING'
+			AND next_run_at <= NOW()
+	`, queueName).Scan(&depth)
+	if err != nil {
+		return 0, err
+	}
+	return depth, nil
+}
+
+const scheduleColumns = `schedule_id, name, cron_expr, timezone, queue_name, job_type, payload,
+			missed_run_policy, enabled, priority, max_attempts, initial_delay, backoff, max_delay, jitter,
+			next_fire_at, last_fire_at, created_at, updated_at`
+
+func scanSchedule(row pgx.Row) (ScheduleResponse, error) {
+	var sched ScheduleResponse
+	err := row.Scan(
+		&sched.ScheduleID,
+		&sched.Name,
+		&sched.Cron,
+		&sched.Timezone,
+		&sched.Queue,
+		&sched.JobType,
+		&sched.Payload,
+		&sched.MissedRunPolicy,
+		&sched.Enabled,
+		&sched.Priority,
+		&sched.MaxAttempts,
+		&sched.InitialDelay,
+		&sched.Backoff,
+		&sched.MaxDelay,
+		&sched.Jitter,
+		&sched.NextFireAt,
+		&sched.LastFireAt,
+		&sched.CreatedAt,
+		&sched.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ScheduleResponse{}, errNotFound
+	}
+	return sched, err
+}
+
+func (s *PostgresStore) CreateSchedule(ctx context.Context, sched ScheduleResponse) (ScheduleResponse, error) {
+	return scanSchedule(s.pool.QueryRow(ctx, `
+		INSERT INTO schedules (
+			schedule_id, name, cron_expr, timezone, queue_name, job_type, payload, missed_run_policy,
+			enabled, max_attempts, initial_delay, backoff, max_delay, jitter, next_fire_at, priority
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING `+scheduleColumns,
+		sched.ScheduleID, sched.Name, sched.Cron, sched.Timezone, sched.Queue, sched.JobType, sched.Payload,
+		sched.MissedRunPolicy, sched.Enabled, sched.MaxAttempts, sched.InitialDelay, sched.Backoff,
+		sched.MaxDelay, sched.Jitter, sched.NextFireAt, sched.Priority,
+	))
+}
+
+func (s *PostgresStore) GetSchedule(ctx context.Context, scheduleID string) (ScheduleResponse, error) {
+	return scanSchedule(s.pool.QueryRow(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM schedules
+		WHERE schedule_id = $1
+	`, scheduleID))
+}
+
+func (s *PostgresStore) ListSchedules(ctx context.Context, limit, offset int) ([]ScheduleResponse, int, error) {
+	var total int
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(1) FROM schedules`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM schedules
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []ScheduleResponse
+	for rows.Next() {
+		sched, err := scanSchedule(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, sched)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (s *PostgresStore) UpdateSchedule(ctx context.Context, sched ScheduleResponse) (ScheduleResponse, error) {
+	return scanSchedule(s.pool.QueryRow(ctx, `
+		UPDATE schedules
+		SET name = $2,
+			cron_expr = $3,
+			timezone = $4,
+			queue_name = $5,
+			job_type = $6,
+			payload = $7,
+			missed_run_policy = $8,
+			enabled = $9,
+			max_attempts = $10,
+			initial_delay = $11,
+			backoff = $12,
+			max_delay = $13,
+			jitter = $14,
+			next_fire_at = $15,
+			priority = $16,
+			updated_at = NOW()
+		WHERE schedule_id = $1
+		RETURNING `+scheduleColumns,
+		sched.ScheduleID, sched.Name, sched.Cron, sched.Timezone, sched.Queue, sched.JobType, sched.Payload,
+		sched.MissedRunPolicy, sched.Enabled, sched.MaxAttempts, sched.InitialDelay, sched.Backoff,
+		sched.MaxDelay, sched.Jitter, sched.NextFireAt, sched.Priority,
+	))
+}
+
+func (s *PostgresStore) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM schedules WHERE schedule_id = $1`, scheduleID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errNotFound
+	}
+	return nil
+}
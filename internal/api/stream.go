@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// eventReplayBatch is how many logged events one query fetches when a
+	// stream resumes from Last-Event-ID.
+	eventReplayBatch = 500
+	// streamPingInterval keeps idle streams from being closed by proxies.
+	streamPingInterval = 15 * time.Second
+)
+
+// streamEvents serves GET /events as Server-Sent Events. Each job state
+// change matching the queue, jobType, state and jobId filters (comma-separated
+// lists) is sent as an event named "state" whose id is the job_events ID. A
+// client that reconnects with Last-Event-ID first gets the events it missed
+// from the log, then live ones.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "invalid_method", "method not allowed", nil)
+		return
+	}
+	if s.events == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "events_unavailable", "event stream is not enabled", nil)
+		return
+	}
+
+	filter := parseEventFilter(r.URL.Query())
+	lastID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastID == "" {
+		// For clients that cannot set headers.
+		lastID = strings.TrimSpace(r.URL.Query().Get("lastEventId"))
+	}
+	var afterID int64
+	resume := lastID != ""
+	if resume {
+		id, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil || id < 0 {
+			writeAPIError(w, http.StatusBadRequest, "invalid_last_event_id", "Last-Event-ID must be an event id", nil)
+			return
+		}
+		afterID = id
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server-wide WriteTimeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// The backlog is replayed before subscribing, so however long it is, the
+	// subscriber buffer only has to hold what arrives during the catch-up.
+	if resume {
+		last, err := s.replayEvents(r.Context(), w, afterID, filter, 0, nil)
+		if err != nil {
+			// The client reconnects with the last ID it received.
+			s.logger.Warn("replay events failed", "after_id", afterID, "err", err)
+			return
+		}
+		afterID = last
+	}
+
+	st := s.events.subscribe(filter)
+	defer s.events.unsubscribe(st)
+
+	// The catch-up sends what was committed while the backlog was replayed.
+	// Of those, only the ones published after subscribing can arrive live
+	// again, so only they are remembered, and each is forgotten once it does.
+	replayed := map[int64]struct{}{}
+	if resume {
+		if _, err := s.replayEvents(r.Context(), w, afterID, filter, st.since, replayed); err != nil {
+			s.logger.Warn("replay events failed", "after_id", afterID, "err", err)
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case ev, ok := <-st.ch:
+			if !ok {
+				// Dropped by the hub; the client resumes from the log.
+				return
+			}
+			if _, sent := replayed[ev.ID]; sent {
+				delete(replayed, ev.ID)
+				continue
+			}
+			if err := writeStateEvent(w, ev); err != nil {
+				return
+			}
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// replayEvents writes the logged events after afterID, adds the IDs above
+// liveAfter to sent if it is not nil, and returns the ID of the last one
+// written.
+func (s *Server) replayEvents(ctx context.Context, w http.ResponseWriter, afterID int64, filter EventFilter, liveAfter int64, sent map[int64]struct{}) (int64, error) {
+	for {
+		qctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		items, err := s.store.ListEventsSince(qctx, afterID, filter, eventReplayBatch)
+		cancel()
+		if err != nil {
+			return afterID, err
+		}
+		for _, ev := range items {
+			if err := writeStateEvent(w, ev); err != nil {
+				return afterID, err
+			}
+			if sent != nil && ev.ID > liveAfter {
+				sent[ev.ID] = struct{}{}
+			}
+			afterID = ev.ID
+		}
+		if len(items) < eventReplayBatch {
+			return afterID, nil
+		}
+	}
+}
+
+func writeStateEvent(w http.ResponseWriter, ev JobStateEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: state\ndata: %s\n\n", ev.ID, data)
+	return err
+}
+
+func parseEventFilter(qp url.Values) EventFilter {
+	return EventFilter{
+		Queues:   splitList(qp.Get("queue")),
+		JobTypes: splitList(qp.Get("jobType")),
+		States:   splitList(qp.Get("state")),
+		JobIDs:   splitList(qp.Get("jobId")),
+	}
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -8,15 +8,25 @@ const els = {
   next: document.getElementById("next"),
   page: document.getElementById("page"),
   state: document.getElementById("state"),
+  priority: document.getElementById("priority"),
   jobType: document.getElementById("jobType"),
   q: document.getElementById("q"),
   kTotal: document.getElementById("kTotal"),
   kPending: document.getElementById("kPending"),
   kFailed: document.getElementById("kFailed"),
   kDLQ: document.getElementById("kDLQ"),
+  kCanceled: document.getElementById("kCanceled"),
   chart: document.getElementById("chart"),
+  detail: document.getElementById("detail"),
+  detailTitle: document.getElementById("detailTitle"),
+  detailMeta: document.getElementById("detailMeta"),
+  detailClose: document.getElementById("detailClose"),
+  attemptRows: document.getElementById("attemptRows"),
+  timeline: document.getElementById("timeline"),
 };
 
+let detailId = null;
+
 async function apiGet(url) {
   const r = await fetch(url);
   if (!r.ok) throw new Error(await r.text());
@@ -28,6 +38,10 @@ async function apiPost(url) {
   if (!r.ok) throw new Error(await r.text());
 }
 
+function esc(v) {
+  return String(v ?? "").replace(/[&<>"']/g, (c) => `&#${c.charCodeAt(0)};`);
+}
+
 function qs(obj) {
   const p = new URLSearchParams();
   Object.entries(obj).forEach(([k, v]) => {
@@ -100,6 +114,7 @@ async function loadStats() {
   els.kPending.textContent = String(data.pending ?? 0);
   els.kFailed.textContent = String(data.failed ?? 0);
   els.kDLQ.textContent = String(data.dlq ?? 0);
+  els.kCanceled.textContent = String(data.canceled ?? 0);
 
   if (Array.isArray(data.points) && data.points.length > 0) {
     const p = data.points[0];
@@ -116,6 +131,7 @@ async function loadJobs() {
     limit,
     offset,
     state: els.state.value,
+    priority: els.priority.value,
     jobType: els.jobType.value,
     q: els.q.value,
   };
@@ -125,13 +141,15 @@ async function loadJobs() {
 
   data.items.forEach((j) => {
     const tr = document.createElement("tr");
+    tr.dataset.id = j.jobId;
     tr.innerHTML = `
-      <td>${j.jobId}</td>
+      <td><button data-a="view" data-id="${j.jobId}">${j.jobId}</button></td>
       <td>${j.jobType}</td>
-      <td>${j.state}</td>
+      <td class="state">${j.state}</td>
+      <td>${j.priority ?? 0}</td>
       <td>${j.retryCount}/${j.maxRetries}</td>
       <td>${new Date(j.createdAt).toLocaleString()}</td>
-      <td>${new Date(j.updatedAt).toLocaleString()}</td>
+      <td class="updated">${new Date(j.updatedAt).toLocaleString()}</td>
       <td>
         <button data-a="retry" data-id="${j.jobId}">Retry</button>
         <button data-a="dlq" data-id="${j.jobId}">DLQ</button>
@@ -143,8 +161,109 @@ async function loadJobs() {
   els.page.textContent = String(offset / limit + 1);
 }
 
+function outcomeBadge(outcome) {
+  const cls = { succeeded: "ok", running: "", failed: "warn", canceled: "warn" }[outcome] ?? "bad";
+  return `<span class="badge ${cls}">${esc(outcome)}</span>`;
+}
+
+async function loadDetail() {
+  if (!detailId) return;
+
+  const [job, attempts, events] = await Promise.all([
+    apiGet(`/jobs/${detailId}`),
+    apiGet(`/jobs/${detailId}/attempts`),
+    apiGet(`/jobs/${detailId}/events`),
+  ]);
+
+  els.detailTitle.textContent = `Job ${job.jobId}`;
+  els.detailMeta.textContent = `${job.jobType} · ${job.queue} · ${job.state} · attempt ${job.attemptCount}/${job.maxAttempts}`;
+  els.timeline.innerHTML = "";
+  events.items.forEach((e) => {
+    const li = document.createElement("li");
+    const from = e.fromState ? `${esc(e.fromState)} → ` : "";
+    li.innerHTML = `
+      <span class="when">${new Date(e.createdAt).toLocaleString()}</span>
+      <span class="what">${from}<b>${esc(e.toState)}</b></span>
+      <span class="who">${esc(e.actor)}</span>
+      <span class="why">${esc(e.reason)}</span>
+    `;
+    els.timeline.appendChild(li);
+  });
+
+  els.attemptRows.innerHTML = "";
+
+  attempts.items.forEach((a) => {
+    const tr = document.createElement("tr");
+    tr.innerHTML = `
+      <td>${a.attempt}</td>
+      <td>${outcomeBadge(a.outcome)}</td>
+      <td>${esc(a.leaseOwner)}</td>
+      <td>${new Date(a.startedAt).toLocaleString()}</td>
+      <td>${a.durationMs != null ? `${a.durationMs} ms` : "-"}</td>
+      <td>${esc(a.failureClass)}</td>
+      <td class="err">${esc(a.error)}</td>
+    `;
+    els.attemptRows.appendChild(tr);
+  });
+}
+
+async function showDetail(id) {
+  detailId = id;
+  els.detail.hidden = false;
+  try {
+    await loadDetail();
+  } catch (err) {
+    console.error(err);
+  }
+  els.detail.scrollIntoView({ behavior: "smooth" });
+}
+
 async function refreshAll() {
-  await Promise.allSettled([loadJobs(), loadStats()]);
+  await Promise.allSettled([loadJobs(), loadStats(), loadDetail()]);
+}
+
+// Live updates: GET /events pushes every state change. Visible rows are
+// patched in place; stats, the detail card and (for new jobs on the first
+// page) the list are reloaded at most once per burst.
+let queued = new Set();
+let flushTimer = null;
+
+function schedule(fn) {
+  queued.add(fn);
+  if (flushTimer) return;
+  flushTimer = setTimeout(() => {
+    const fns = queued;
+    queued = new Set();
+    flushTimer = null;
+    fns.forEach((f) => f().catch((err) => console.error(err)));
+  }, 500);
+}
+
+function applyEvent(ev) {
+  const tr = els.rows.querySelector(`tr[data-id="${CSS.escape(ev.jobId)}"]`);
+  if (tr) {
+    tr.querySelector(".state").textContent = ev.toState;
+    tr.querySelector(".updated").textContent = new Date(ev.createdAt).toLocaleString();
+  } else if (!ev.fromState && offset === 0) {
+    schedule(loadJobs);
+  }
+  if (ev.jobId === detailId) schedule(loadDetail);
+  schedule(loadStats);
+}
+
+function connectEvents() {
+  if (!window.EventSource) return false;
+  const es = new EventSource("/events");
+  es.addEventListener("state", (e) => {
+    try {
+      applyEvent(JSON.parse(e.data));
+    } catch (err) {
+      console.error(err);
+    }
+  });
+  // EventSource reconnects on its own and resumes with Last-Event-ID.
+  es.onerror = () => console.warn("event stream interrupted, reconnecting");
+  return true;
 }
 
 els.rows.addEventListener("click", async (e) => {
@@ -154,6 +273,11 @@ els.rows.addEventListener("click", async (e) => {
   const id = b.dataset.id;
   const a = b.dataset.a;
 
+  if (a === "view") {
+    showDetail(id);
+    return;
+  }
+
   try {
     await apiPost(`/jobs/${id}/${a}`);
   } catch (err) {
@@ -163,6 +287,11 @@ els.rows.addEventListener("click", async (e) => {
   refreshAll();
 });
 
+els.detailClose.onclick = () => {
+  detailId = null;
+  els.detail.hidden = true;
+};
+
 els.refresh.onclick = () => {
   offset = 0;
   refreshAll();
@@ -183,6 +312,11 @@ els.state.onchange = () => {
   loadJobs();
 };
 
+els.priority.onchange = () => {
+  offset = 0;
+  loadJobs();
+};
+
 els.jobType.onkeydown = (e) => {
   if (e.key === "Enter") {
     offset = 0;
@@ -197,5 +331,7 @@ els.q.onkeydown = (e) => {
   }
 };
 
-setInterval(refreshAll, 2500);
+// With the stream connected the timer only catches what it cannot patch,
+// e.g. rows moving between pages.
+setInterval(refreshAll, connectEvents() ? 30000 : 2500);
 refreshAll();
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 60 * time.Second
+	// waitPollInterval is how often a waiter rechecks its job when the server
+	// has no EventHub.
+	waitPollInterval = time.Second
+)
+
+// waitJob long-polls until the job reaches COMPLETED, DLQ or CANCELED, the
+// timeout passes or the server shuts down, then returns its status and, if it
+// completed with one, its result.
+// With an EventHub the job is only re-read when the feed reports a change to
+// it, so idle waiters cost no queries.
+func (s *Server) waitJob(w http.ResponseWriter, r *http.Request, jobID string) {
+	timeout := defaultWaitTimeout
+	if v := strings.TrimSpace(r.URL.Query().Get("timeout")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			writeAPIError(w, http.StatusBadRequest, "invalid_timeout", "timeout must be a duration like 30s", nil)
+			return
+		}
+		timeout = min(d, maxWaitTimeout)
+	}
+	// The server-wide WriteTimeout is shorter than a long poll.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 10*time.Second))
+
+	// Watch before the first read so a change between the two is not missed.
+	var changed <-chan struct{}
+	var poll <-chan time.Time
+	if s.events != nil {
+		ch, stop := s.events.watch(jobID)
+		defer stop()
+		changed = ch
+	} else {
+		ticker := time.NewTicker(waitPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	timedOut := false
+	for {
+		resp, err := s.readWaitResponse(r.Context(), jobID)
+		if err != nil {
+			status, code, message := mapDomainError(err, http.StatusInternalServerError, "internal_error", "failed to fetch job")
+			writeAPIError(w, status, code, message, nil)
+			return
+		}
+		if resp.Done || timedOut {
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-poll:
+		case <-deadline.C:
+			timedOut = true
+		case <-s.closing:
+			// Answer now rather than holding up Shutdown until the timeout.
+			timedOut = true
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) readWaitResponse(ctx context.Context, jobID string) (JobWaitResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	job, err := s.store.GetJob(ctx, jobID)
+	if err != nil {
+		return JobWaitResponse{}, err
+	}
+	resp := JobWaitResponse{
+		Job:  job,
+		Done: job.State == StateCompleted || job.State == StateDLQ || job.State == StateCanceled,
+	}
+	if job.State == StateCompleted && job.HasResult {
+		res, err := s.store.GetJobResult(ctx, jobID)
+		if err != nil {
+			return JobWaitResponse{}, err
+		}
+		resp.Result = res.Data
+		resp.ResultContentType = res.ContentType
+		if resp.ResultContentType == "" {
+			resp.ResultContentType = "application/json"
+		}
+	}
+	return resp, nil
+}
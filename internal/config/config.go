@@ -3,13 +3,18 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	HTTPAddr          string
+	MetricsAddr       string
 	QueueName         string
+	Queues            []string
+	QueueBackend      string
 	UIDir             string
 	LogLevel          string
 	PostgresDSN       string
@@ -17,9 +22,37 @@ type Config struct {
 	RedisPassword     string
 	RedisDB           int
 	WorkerConcurrency int
-	RateLimitPerSec   int
-	TracingEnabled    bool
-	TracingExporter   string
+	WorkerShutdown    time.Duration
+	WorkerQueues      []WorkerQueue
+	WorkerQueueMode   string
+	WorkerWakeup      string
+	WorkerWakeupWait  time.Duration
+	// WorkerJobTimeout bounds each attempt of a job submitted without a
+	// timeout whose type has no entry in WorkerJobTimeouts. Zero means none.
+	WorkerJobTimeout  time.Duration
+	WorkerJobTimeouts map[string]time.Duration
+	// WorkerRetryPanics makes a handler panic a retryable failure; by default
+	// it is terminal.
+	WorkerRetryPanics bool
+	ReconcileInterval time.Duration
+	OutboxInterval    time.Duration
+	PriorityAging     time.Duration
+	// LeaseExpiryLimit is how many times a job's lease may expire before
+	// the reaper dead-letters it. Zero leaves only max_attempts as the bound.
+	LeaseExpiryLimit int
+	RateLimitPerSec  int
+	TracingEnabled   bool
+	TracingExporter  string
+}
+
+// WorkerQueue is one queue a worker process leases from. Weight is the
+// queue's share of lease attempts in weighted mode and its rank in strict
+// mode. Concurrency caps the queue's in-flight jobs; zero means no cap beyond
+// WORKER_CONCURRENCY.
+type WorkerQueue struct {
+	Name        string
+	Weight      int
+	Concurrency int
 }
 
 type Error struct {
@@ -41,10 +74,42 @@ func Load() (Config, error) {
 	if err != nil {
 		issues = append(issues, err.Error())
 	}
+	workerShutdown, err := getEnvDuration("WORKER_SHUTDOWN_TIMEOUT", 30*time.Second)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
+	workerWakeupWait, err := getEnvDuration("WORKER_WAKEUP_TIMEOUT", 5*time.Second)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
+	workerJobTimeout, err := getEnvDuration("WORKER_JOB_TIMEOUT", 0)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
+	reconcileInterval, err := getEnvDuration("QUEUE_RECONCILE_INTERVAL", 30*time.Second)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
+	outboxInterval, err := getEnvDuration("OUTBOX_RELAY_INTERVAL", time.Second)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
+	priorityAging, err := getEnvDuration("PRIORITY_AGING_INTERVAL", time.Minute)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
+	leaseExpiryLimit, err := getEnvInt("LEASE_EXPIRY_LIMIT", 3)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
 	rateLimitPerSec, err := getEnvInt("RATE_LIMIT_PER_SEC", 0)
 	if err != nil {
 		issues = append(issues, err.Error())
 	}
+	workerRetryPanics, err := getEnvBool("WORKER_RETRY_PANICS", false)
+	if err != nil {
+		issues = append(issues, err.Error())
+	}
 	tracingEnabled, err := getEnvBool("TRACING_ENABLED", false)
 	if err != nil {
 		issues = append(issues, err.Error())
@@ -52,7 +117,9 @@ func Load() (Config, error) {
 
 	cfg := Config{
 		HTTPAddr:          getEnv("HTTP_ADDR", ":8080"),
+		MetricsAddr:       getEnv("METRICS_ADDR", ":9100"),
 		QueueName:         getEnv("QUEUE_NAME", "jobs:ready"),
+		QueueBackend:      strings.ToLower(getEnv("QUEUE_BACKEND", "redis")),
 		UIDir:             getEnv("UI_DIR", "./internal/api/ui"),
 		LogLevel:          strings.ToLower(getEnv("LOG_LEVEL", "info")),
 		PostgresDSN:       strings.TrimSpace(os.Getenv("POSTGRES_DSN")),
@@ -60,6 +127,16 @@ func Load() (Config, error) {
 		RedisPassword:     getEnv("REDIS_PASSWORD", ""),
 		RedisDB:           redisDB,
 		WorkerConcurrency: workerConcurrency,
+		WorkerShutdown:    workerShutdown,
+		WorkerQueueMode:   strings.ToLower(getEnv("WORKER_QUEUE_MODE", "weighted")),
+		WorkerWakeup:      strings.ToLower(getEnv("WORKER_WAKEUP", "poll")),
+		WorkerWakeupWait:  workerWakeupWait,
+		WorkerJobTimeout:  workerJobTimeout,
+		WorkerRetryPanics: workerRetryPanics,
+		ReconcileInterval: reconcileInterval,
+		OutboxInterval:    outboxInterval,
+		PriorityAging:     priorityAging,
+		LeaseExpiryLimit:  leaseExpiryLimit,
 		RateLimitPerSec:   rateLimitPerSec,
 		TracingEnabled:    tracingEnabled,
 		TracingExporter:   strings.ToLower(getEnv("TRACING_EXPORTER", "stdout")),
@@ -71,12 +148,16 @@ func Load() (Config, error) {
 	if cfg.HTTPAddr == "" {
 		issues = append(issues, "HTTP_ADDR must not be empty")
 	}
-	if cfg.RedisAddr == "" {
+	if cfg.QueueBackend != "redis" && cfg.QueueBackend != "postgres" {
+		issues = append(issues, fmt.Sprintf("QUEUE_BACKEND must be redis or postgres (got %q)", cfg.QueueBackend))
+	}
+	if cfg.QueueBackend == "redis" && cfg.RedisAddr == "" {
 		issues = append(issues, "REDIS_ADDR must not be empty")
 	}
 	if cfg.QueueName == "" {
 		issues = append(issues, "QUEUE_NAME must not be empty")
 	}
+	cfg.Queues = parseQueues(cfg.QueueName, os.Getenv("QUEUES"))
 	if cfg.LogLevel != "debug" && cfg.LogLevel != "info" && cfg.LogLevel != "warn" && cfg.LogLevel != "error" {
 		issues = append(issues, fmt.Sprintf("LOG_LEVEL must be one of debug, info, warn, error (got %q)", cfg.LogLevel))
 	}
@@ -86,6 +167,51 @@ func Load() (Config, error) {
 	if cfg.WorkerConcurrency <= 0 {
 		issues = append(issues, "WORKER_CONCURRENCY must be >= 1")
 	}
+	if cfg.WorkerShutdown < 0 {
+		issues = append(issues, "WORKER_SHUTDOWN_TIMEOUT must be >= 0")
+	}
+	workerQueues, queueIssues := parseWorkerQueues(cfg.QueueName, os.Getenv("WORKER_QUEUES"), os.Getenv("WORKER_QUEUE_CONCURRENCY"))
+	cfg.WorkerQueues = workerQueues
+	issues = append(issues, queueIssues...)
+	// A worker on a queue nothing can be submitted to would poll it forever.
+	for _, q := range workerQueues {
+		if !slices.Contains(cfg.Queues, q.Name) {
+			issues = append(issues, fmt.Sprintf("WORKER_QUEUES queue %q must be QUEUE_NAME or listed in QUEUES", q.Name))
+		}
+	}
+	if cfg.WorkerQueueMode != "weighted" && cfg.WorkerQueueMode != "strict" {
+		issues = append(issues, fmt.Sprintf("WORKER_QUEUE_MODE must be weighted or strict (got %q)", cfg.WorkerQueueMode))
+	}
+	switch cfg.WorkerWakeup {
+	case "poll", "notify":
+	case "redis":
+		if !cfg.UsesRedis() {
+			issues = append(issues, "WORKER_WAKEUP=redis requires QUEUE_BACKEND=redis")
+		}
+	default:
+		issues = append(issues, fmt.Sprintf("WORKER_WAKEUP must be poll, notify or redis (got %q)", cfg.WorkerWakeup))
+	}
+	if cfg.WorkerWakeupWait <= 0 {
+		issues = append(issues, "WORKER_WAKEUP_TIMEOUT must be > 0")
+	}
+	if cfg.WorkerJobTimeout < 0 {
+		issues = append(issues, "WORKER_JOB_TIMEOUT must be >= 0")
+	}
+	jobTimeouts, timeoutIssues := parseJobTimeouts(os.Getenv("WORKER_JOB_TIMEOUTS"))
+	cfg.WorkerJobTimeouts = jobTimeouts
+	issues = append(issues, timeoutIssues...)
+	if cfg.ReconcileInterval < 0 {
+		issues = append(issues, "QUEUE_RECONCILE_INTERVAL must be >= 0")
+	}
+	if cfg.OutboxInterval <= 0 {
+		issues = append(issues, "OUTBOX_RELAY_INTERVAL must be > 0")
+	}
+	if cfg.PriorityAging < 0 {
+		issues = append(issues, "PRIORITY_AGING_INTERVAL must be >= 0")
+	}
+	if cfg.LeaseExpiryLimit < 0 {
+		issues = append(issues, "LEASE_EXPIRY_LIMIT must be >= 0")
+	}
 	if cfg.RateLimitPerSec < 0 {
 		issues = append(issues, "RATE_LIMIT_PER_SEC must be >= 0")
 	}
@@ -99,6 +225,118 @@ func Load() (Config, error) {
 	return cfg, nil
 }
 
+// UsesRedis reports whether job IDs are published to Redis. With
+// QUEUE_BACKEND=postgres, Postgres is the only dependency.
+func (c Config) UsesRedis() bool {
+	return c.QueueBackend == "redis"
+}
+
+// parseQueues returns the known queue names from a comma-separated list. The
+// default queue is always known and comes first.
+func parseQueues(defaultQueue, raw string) []string {
+	queues := []string{defaultQueue}
+	seen := map[string]bool{defaultQueue: true}
+	for _, q := range strings.Split(raw, ",") {
+		q = strings.TrimSpace(q)
+		if q == "" || seen[q] {
+			continue
+		}
+		seen[q] = true
+		queues = append(queues, q)
+	}
+	return queues
+}
+
+// parseWorkerQueues parses WORKER_QUEUES ("critical=5,default=3,bulk=1") and
+// WORKER_QUEUE_CONCURRENCY ("bulk=2"). A queue listed without a weight gets
+// weight 1. Without WORKER_QUEUES the worker serves only the default queue.
+func parseWorkerQueues(defaultQueue, rawWeights, rawCaps string) ([]WorkerQueue, []string) {
+	var issues []string
+	var queues []WorkerQueue
+	index := map[string]int{}
+	for _, entry := range strings.Split(rawWeights, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, weight := entry, 1
+		if n, w, ok := strings.Cut(entry, "="); ok {
+			name = strings.TrimSpace(n)
+			v, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil || v < 1 {
+				issues = append(issues, fmt.Sprintf("WORKER_QUEUES weight for %q must be an integer >= 1 (got %q)", name, w))
+				continue
+			}
+			weight = v
+		}
+		if name == "" {
+			issues = append(issues, fmt.Sprintf("WORKER_QUEUES entry %q has no queue name", entry))
+			continue
+		}
+		if _, dup := index[name]; dup {
+			issues = append(issues, fmt.Sprintf("WORKER_QUEUES lists %q more than once", name))
+			continue
+		}
+		index[name] = len(queues)
+		queues = append(queues, WorkerQueue{Name: name, Weight: weight})
+	}
+	if len(queues) == 0 {
+		queues = []WorkerQueue{{Name: defaultQueue, Weight: 1}}
+		index[defaultQueue] = 0
+	}
+
+	for _, entry := range strings.Split(rawCaps, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, c, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("WORKER_QUEUE_CONCURRENCY entry %q must be queue=limit", entry))
+			continue
+		}
+		i, known := index[name]
+		if !known {
+			issues = append(issues, fmt.Sprintf("WORKER_QUEUE_CONCURRENCY names %q, which the worker does not serve", name))
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(c))
+		if err != nil || v < 1 {
+			issues = append(issues, fmt.Sprintf("WORKER_QUEUE_CONCURRENCY limit for %q must be an integer >= 1 (got %q)", name, c))
+			continue
+		}
+		queues[i].Concurrency = v
+	}
+	return queues, issues
+}
+
+// parseJobTimeouts parses WORKER_JOB_TIMEOUTS ("email.send=30s,report=10m"),
+// the per-job-type execution timeouts.
+func parseJobTimeouts(raw string) (map[string]time.Duration, []string) {
+	var issues []string
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		jobType, v, ok := strings.Cut(entry, "=")
+		jobType = strings.TrimSpace(jobType)
+		if !ok || jobType == "" {
+			issues = append(issues, fmt.Sprintf("WORKER_JOB_TIMEOUTS entry %q must be jobType=duration", entry))
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d <= 0 {
+			issues = append(issues, fmt.Sprintf("WORKER_JOB_TIMEOUTS timeout for %q must be a positive duration (got %q)", jobType, v))
+			continue
+		}
+		timeouts[jobType] = d
+	}
+	return timeouts, issues
+}
+
 func getEnv(key, fallback string) string {
 	v := strings.TrimSpace(os.Getenv(key))
 	if v == "" {
@@ -119,6 +357,18 @@ func getEnvInt(key string, fallback int) (int, error) {
 	return n, nil
 }
 
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a valid duration like 30s or 1m (got %q)", key, v)
+	}
+	return d, nil
+}
+
 func getEnvBool(key string, fallback bool) (bool, error) {
 	v := strings.TrimSpace(os.Getenv(key))
 	if v == "" {
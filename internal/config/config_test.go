@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadFailsWhenPostgresDSNMissing(t *testing.T) {
@@ -42,3 +43,154 @@ func TestLoadFailsOnInvalidBooleanEnv(t *testing.T) {
 		t.Fatalf("expected boolean parse error, got: %v", err)
 	}
 }
+
+func TestLoadFailsOnInvalidDurationEnv(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("WORKER_SHUTDOWN_TIMEOUT", "soon")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid WORKER_SHUTDOWN_TIMEOUT")
+	}
+	if !strings.Contains(err.Error(), `WORKER_SHUTDOWN_TIMEOUT must be a valid duration`) {
+		t.Fatalf("expected duration parse error, got: %v", err)
+	}
+}
+
+func TestLoadQueuesIncludesDefaultQueue(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("QUEUE_NAME", "default")
+	t.Setenv("QUEUES", "critical, bulk,default,,critical")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	want := []string{"default", "critical", "bulk"}
+	if strings.Join(cfg.Queues, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected queues %v, got %v", want, cfg.Queues)
+	}
+}
+
+func TestLoadQueueBackend(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("QUEUE_BACKEND", "postgres")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.UsesRedis() {
+		t.Fatal("expected postgres backend not to use redis")
+	}
+
+	t.Setenv("QUEUE_BACKEND", "kafka")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "QUEUE_BACKEND") {
+		t.Fatalf("expected QUEUE_BACKEND error, got: %v", err)
+	}
+}
+
+func TestLoadRejectsRedisWakeupWithoutRedis(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("QUEUE_BACKEND", "postgres")
+	t.Setenv("WORKER_WAKEUP", "redis")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "WORKER_WAKEUP=redis requires QUEUE_BACKEND=redis") {
+		t.Fatalf("expected WORKER_WAKEUP error, got: %v", err)
+	}
+}
+
+func TestLoadWorkerQueues(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("QUEUES", "critical,default,bulk")
+	t.Setenv("WORKER_QUEUES", "critical=5, default=3,bulk")
+	t.Setenv("WORKER_QUEUE_CONCURRENCY", "bulk=2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	want := []WorkerQueue{{"critical", 5, 0}, {"default", 3, 0}, {"bulk", 1, 2}}
+	if len(cfg.WorkerQueues) != len(want) {
+		t.Fatalf("expected %v, got %v", want, cfg.WorkerQueues)
+	}
+	for i := range want {
+		if cfg.WorkerQueues[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, cfg.WorkerQueues)
+		}
+	}
+	if cfg.WorkerQueueMode != "weighted" {
+		t.Fatalf("expected weighted mode by default, got %q", cfg.WorkerQueueMode)
+	}
+}
+
+func TestLoadFailsOnInvalidWorkerQueues(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("WORKER_QUEUES", "critical=0")
+	t.Setenv("WORKER_QUEUE_CONCURRENCY", "bulk=2")
+	t.Setenv("WORKER_QUEUE_MODE", "random")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, key := range []string{"WORKER_QUEUES", "WORKER_QUEUE_CONCURRENCY", "WORKER_QUEUE_MODE"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Fatalf("expected %s issue, got: %v", key, err)
+		}
+	}
+}
+
+func TestLoadFailsOnUnknownWorkerQueue(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("QUEUES", "critical")
+	t.Setenv("WORKER_QUEUES", "critical=2,criticle")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), `WORKER_QUEUES queue "criticle"`) {
+		t.Fatalf("expected unknown worker queue error, got: %v", err)
+	}
+	if strings.Contains(err.Error(), `queue "critical"`) {
+		t.Fatalf("expected the known queue to be accepted, got: %v", err)
+	}
+}
+
+func TestLoadWorkerJobTimeouts(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+	t.Setenv("WORKER_JOB_TIMEOUT", "5m")
+	t.Setenv("WORKER_JOB_TIMEOUTS", "email.send=30s, report.build=1h")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.WorkerJobTimeout != 5*time.Minute {
+		t.Fatalf("expected 5m default, got %s", cfg.WorkerJobTimeout)
+	}
+	if cfg.WorkerJobTimeouts["email.send"] != 30*time.Second || cfg.WorkerJobTimeouts["report.build"] != time.Hour {
+		t.Fatalf("unexpected per-type timeouts: %v", cfg.WorkerJobTimeouts)
+	}
+
+	t.Setenv("WORKER_JOB_TIMEOUTS", "email.send=soon")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "WORKER_JOB_TIMEOUTS") {
+		t.Fatalf("expected WORKER_JOB_TIMEOUTS issue, got %v", err)
+	}
+}
+
+func TestLoadLeaseExpiryLimit(t *testing.T) {
+	t.Setenv("POSTGRES_DSN", "postgres://example")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.LeaseExpiryLimit != 3 {
+		t.Fatalf("expected default limit 3, got %d", cfg.LeaseExpiryLimit)
+	}
+
+	t.Setenv("LEASE_EXPIRY_LIMIT", "-1")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "LEASE_EXPIRY_LIMIT") {
+		t.Fatalf("expected LEASE_EXPIRY_LIMIT issue, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+// Package cron parses standard five-field cron expressions and computes their
+// fire times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the zone database so schedules resolve time zones in minimal images.
+	_ "time/tzdata"
+)
+
+// Expression is a parsed cron expression: minute hour day-of-month month day-of-week.
+type Expression struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// Per cron convention, when both day fields are restricted a day matches
+	// if either does; otherwise both must match. A field starting with "*"
+	// (including a stepped "*/2") counts as unrestricted.
+	domAny bool
+	dowAny bool
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day-of-month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// 7 is accepted as an alias for Sunday.
+	dowField = field{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a five-field cron expression or one of the @yearly, @monthly,
+// @weekly, @daily, @midnight and @hourly macros.
+func Parse(expr string) (*Expression, error) {
+	expr = strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(expr)]; ok {
+		expr = m
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields (got %d)", len(fields))
+	}
+
+	var e Expression
+	var err error
+	if e.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if e.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if e.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if e.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if e.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+	e.domAny = strings.HasPrefix(fields[2], "*") || fields[2] == "?"
+	e.dowAny = strings.HasPrefix(fields[4], "*") || fields[4] == "?"
+	return &e, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		b, err := parsePart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parsePart(part string, f field) (uint64, error) {
+	if part == "" {
+		return 0, fmt.Errorf("%s: empty value", f.name)
+	}
+
+	rangePart, step := part, 1
+	if i := strings.Index(part, "/"); i >= 0 {
+		n, err := strconv.Atoi(part[i+1:])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%s: invalid step in %q", f.name, part)
+		}
+		rangePart, step = part[:i], n
+	}
+
+	lo, hi := f.min, f.max
+	switch {
+	case rangePart == "*" || rangePart == "?":
+	case strings.Contains(rangePart, "-"):
+		bounds := strings.SplitN(rangePart, "-", 2)
+		var err error
+		if lo, err = parseValue(bounds[0], f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(bounds[1], f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("%s: range %q is backwards", f.name, rangePart)
+		}
+	default:
+		v, err := parseValue(rangePart, f)
+		if err != nil {
+			return 0, err
+		}
+		lo = v
+		// "5/15" means starting at 5 every 15; a bare "5" is just 5.
+		if step == 1 {
+			hi = v
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first fire time strictly after t, in t's location. It
+// returns the zero time if the expression has no fire time within five years
+// (e.g. "0 0 30 2 *").
+func (e *Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// advance moves to next, unless time.Date resolved a wall clock inside a DST
+// gap to an instant at or before t; then it steps one minute to keep moving.
+func advance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Minute)
+}
+
+func (e *Expression) dayMatches(t time.Time) bool {
+	domOK := e.dom&(1<<uint(t.Day())) != 0
+	dowOK := e.dow&(1<<uint(t.Weekday())) != 0
+	if e.domAny || e.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
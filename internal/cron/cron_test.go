@@ -0,0 +1,107 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 3, 10, 14, 7, 30, 0, time.UTC) // a Tuesday
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 10, 14, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 10, 14, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * MON-FRI", time.Date(2026, 3, 11, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 jan,jul *", time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{"5/20 14 * * *", time.Date(2026, 3, 10, 14, 25, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matching is enough.
+		{"0 0 13 * FRI", time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		// A stepped "*/2" day of month is unrestricted: both must match.
+		{"0 0 */2 * 1", time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		e, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.expr, err)
+		}
+		if got := e.Next(base); !got.Equal(tc.want) {
+			t.Errorf("%q: expected %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestNextIsStrictlyAfter(t *testing.T) {
+	e, err := Parse("0 * * * *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	at := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	if got := e.Next(at); !got.Equal(at.Add(time.Hour)) {
+		t.Fatalf("expected %s, got %s", at.Add(time.Hour), got)
+	}
+}
+
+func TestNextHonorsLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	e, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	// 2026-03-08 is the spring-forward day in New York.
+	got := e.Next(time.Date(2026, 3, 7, 12, 0, 0, 0, loc))
+	want := time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, got.UTC())
+	}
+	got = e.Next(got)
+	want = time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, got.UTC())
+	}
+}
+
+func TestNextReturnsZeroWhenNeverFiring(t *testing.T) {
+	e, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := e.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Fatalf("expected zero time, got %s", got)
+	}
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"strconv"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -68,6 +69,90 @@ var (
 		},
 		[]string{"queue"},
 	)
+	retriesScheduled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_retries_scheduled_total",
+			Help: "Total failed jobs scheduled for another attempt.",
+		},
+		[]string{"queue"},
+	)
+	retriesExhausted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_retries_exhausted_total",
+			Help: "Total failed jobs moved to the DLQ after exhausting max attempts.",
+		},
+		[]string{"queue"},
+	)
+	jobsSubmitted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_jobs_submitted_total",
+			Help: "Total jobs accepted by the API.",
+		},
+		[]string{"queue", "priority"},
+	)
+	jobsLeased = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_jobs_leased_total",
+			Help: "Total jobs leased by workers.",
+		},
+		[]string{"queue", "priority"},
+	)
+	scheduledRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_scheduled_runs_total",
+			Help: "Total jobs materialized from recurring schedules.",
+		},
+		[]string{"queue"},
+	)
+	scheduledRunsMissed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_scheduled_runs_missed_total",
+			Help: "Total schedule fire times dropped by the missed-run policy.",
+		},
+		[]string{"queue"},
+	)
+	outboxRelayed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_outbox_relayed_total",
+			Help: "Total outbox entries published by the outbox relay.",
+		},
+		[]string{"queue"},
+	)
+	outboxRelayFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_outbox_relay_failures_total",
+			Help: "Total outbox publishes that failed and were rescheduled.",
+		},
+		[]string{"queue"},
+	)
+	jobTimeouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_job_timeouts_total",
+			Help: "Total job attempts canceled for running past their execution timeout.",
+		},
+		[]string{"queue", "job_type"},
+	)
+	workerLeaseLost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_worker_lease_lost_total",
+			Help: "Total running jobs whose handler was canceled because the worker lost the lease.",
+		},
+		[]string{"queue", "reason"},
+	)
+	jobPanics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_job_panics_total",
+			Help: "Total job attempts whose handler panicked.",
+		},
+		[]string{"queue", "job_type"},
+	)
+	poisonJobs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskforge_poison_jobs_total",
+			Help: "Total jobs dead-lettered by the lease reaper after their lease expired repeatedly.",
+		},
+		[]string{"queue", "job_type"},
+	)
 )
 
 func Register(reg *prometheus.Registry) {
@@ -81,6 +166,18 @@ func Register(reg *prometheus.Registry) {
 			workerUtilization,
 			concurrencyThrottled,
 			rateThrottled,
+			retriesScheduled,
+			retriesExhausted,
+			scheduledRuns,
+			scheduledRunsMissed,
+			outboxRelayed,
+			outboxRelayFailures,
+			jobsSubmitted,
+			jobsLeased,
+			workerLeaseLost,
+			jobTimeouts,
+			poisonJobs,
+			jobPanics,
 		)
 	})
 }
@@ -117,22 +214,80 @@ func IncRateThrottled(queue string) {
 	rateThrottled.WithLabelValues(queue).Inc()
 }
 
+func IncRetriesScheduled(queue string) {
+	retriesScheduled.WithLabelValues(queue).Inc()
+}
+
+func IncRetriesExhausted(queue string) {
+	retriesExhausted.WithLabelValues(queue).Inc()
+}
+
+func IncJobsSubmitted(queue string, priority int) {
+	jobsSubmitted.WithLabelValues(queue, strconv.Itoa(priority)).Inc()
+}
+
+func IncJobsLeased(queue string, priority int) {
+	jobsLeased.WithLabelValues(queue, strconv.Itoa(priority)).Inc()
+}
+
+func IncScheduledRuns(queue string) {
+	scheduledRuns.WithLabelValues(queue).Inc()
+}
+
+func AddScheduledRunsMissed(queue string, n int) {
+	scheduledRunsMissed.WithLabelValues(queue).Add(float64(n))
+}
+
+func IncOutboxRelayed(queue string) {
+	outboxRelayed.WithLabelValues(queue).Inc()
+}
+
+func IncOutboxRelayFailures(queue string) {
+	outboxRelayFailures.WithLabelValues(queue).Inc()
+}
+
+func IncJobTimeouts(queue string, jobType string) {
+	jobTimeouts.WithLabelValues(queue, jobType).Inc()
+}
+
+func IncWorkerLeaseLost(queue string, reason string) {
+	workerLeaseLost.WithLabelValues(queue, reason).Inc()
+}
+
+func IncJobPanics(queue string, jobType string) {
+	jobPanics.WithLabelValues(queue, jobType).Inc()
+}
+
+func IncPoisonJobs(queue string, jobType string) {
+	poisonJobs.WithLabelValues(queue, jobType).Inc()
+}
+
+// TerminalCounts are the jobs currently parked in a terminal state that
+// needs attention or explains missing work.
+type TerminalCounts struct {
+	DLQ      int
+	Canceled int
+}
+
 type QueueDLQProvider interface {
-	QueueDepth(ctx context.Context) (int64, error)
-	DLQCount(ctx context.Context) (int, error)
+	QueueDepth(ctx context.Context, queueName string) (int64, error)
+	TerminalCounts(ctx context.Context) (TerminalCounts, error)
 }
 
 type QueueDLQCollector struct {
-	queueName string
-	provider  QueueDLQProvider
-	depthDesc *prometheus.Desc
-	dlqDesc   *prometheus.Desc
+	queueNames   []string
+	provider     QueueDLQProvider
+	depthDesc    *prometheus.Desc
+	dlqDesc      *prometheus.Desc
+	canceledDesc *prometheus.Desc
 }
 
-func NewQueueDLQCollector(queueName string, provider QueueDLQProvider) *QueueDLQCollector {
+// NewQueueDLQCollector reports the depth of each of queueNames and the
+// terminal counts across all queues.
+func NewQueueDLQCollector(queueNames []string, provider QueueDLQProvider) *QueueDLQCollector {
 	return &QueueDLQCollector{
-		queueName: queueName,
-		provider:  provider,
+		queueNames: queueNames,
+		provider:   provider,
 		depthDesc: prometheus.NewDesc(
 			"taskforge_queue_depth",
 			"Current queue depth.",
@@ -145,20 +300,30 @@ func NewQueueDLQCollector(queueName string, provider QueueDLQProvider) *QueueDLQ
 			nil,
 			nil,
 		),
+		canceledDesc: prometheus.NewDesc(
+			"taskforge_canceled_count",
+			"Current count of CANCELED jobs.",
+			nil,
+			nil,
+		),
 	}
 }
 
 func (c *QueueDLQCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.depthDesc
 	ch <- c.dlqDesc
+	ch <- c.canceledDesc
 }
 
 func (c *QueueDLQCollector) Collect(ch chan<- prometheus.Metric) {
 	ctx := context.Background()
-	if depth, err := c.provider.QueueDepth(ctx); err == nil {
-		ch <- prometheus.MustNewConstMetric(c.depthDesc, prometheus.GaugeValue, float64(depth), c.queueName)
+	for _, queueName := range c.queueNames {
+		if depth, err := c.provider.QueueDepth(ctx, queueName); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.depthDesc, prometheus.GaugeValue, float64(depth), queueName)
+		}
 	}
-	if count, err := c.provider.DLQCount(ctx); err == nil {
-		ch <- prometheus.MustNewConstMetric(c.dlqDesc, prometheus.GaugeValue, float64(count))
+	if counts, err := c.provider.TerminalCounts(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.dlqDesc, prometheus.GaugeValue, float64(counts.DLQ))
+		ch <- prometheus.MustNewConstMetric(c.canceledDesc, prometheus.GaugeValue, float64(counts.Canceled))
 	}
 }
@@ -30,10 +30,98 @@ func (r *Redis) Ping(ctx context.Context) error {
 	return r.Client.Ping(ctx).Err()
 }
 
+// WakeupChannel is the pub/sub channel Enqueue announces new job IDs in
+// queueName on.
+func WakeupChannel(queueName string) string {
+	return "taskforge:wakeup:" + queueName
+}
+
+// Enqueue pushes jobID onto the queueName list and announces it to idle
+// workers. The announcement leaves the list untouched, so the list keeps a
+// hint for every ready job that the reconciler only has to prune.
 func (r *Redis) Enqueue(ctx context.Context, queueName string, jobID string) error {
-	return r.Client.LPush(ctx, queueName, jobID).Err()
+	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.LPush(ctx, queueName, jobID)
+		p.Publish(ctx, WakeupChannel(queueName), jobID)
+		return nil
+	})
+	return err
 }
 
 func (r *Redis) QueueDepth(ctx context.Context, queueName string) (int64, error) {
 	return r.Client.LLen(ctx, queueName).Result()
 }
+
+// Wakeups subscribes to the wakeup channels of a set of queues and turns each
+// announcement into a wakeup for one idle worker slot. The announced ID is
+// only a hint: workers lease from Postgres, which stays the source of truth
+// for job state and ordering.
+type Wakeups struct {
+	client     *redis.Client
+	queueNames []string
+	wake       chan struct{}
+}
+
+func NewWakeups(r *Redis, queueNames []string) *Wakeups {
+	return &Wakeups{
+		client:     r.Client,
+		queueNames: queueNames,
+		wake:       make(chan struct{}, 64),
+	}
+}
+
+// Run subscribes until ctx is canceled. The client resubscribes after a
+// dropped connection on its own.
+func (w *Wakeups) Run(ctx context.Context) {
+	channels := make([]string, len(w.queueNames))
+	for i, q := range w.queueNames {
+		channels[i] = WakeupChannel(q)
+	}
+	sub := w.client.Subscribe(ctx, channels...)
+	defer func() { _ = sub.Close() }()
+
+	// Subscription confirmations arrive on every (re)subscribe as well;
+	// announcements made while disconnected are lost, so they wake a slot to
+	// check for work that arrived in the meantime.
+	msgs := sub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-msgs:
+			if !ok {
+				return
+			}
+			select {
+			case w.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Wait blocks until an announcement arrives for one of the subscribed queues
+// or timeout elapses. queueNames is ignored; the queues are fixed when the
+// Wakeups is created.
+func (w *Wakeups) Wait(ctx context.Context, queueNames []string, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-w.wake:
+		return true, nil
+	case <-timer.C:
+		return false, nil
+	case <-ctx.Done():
+		return false, nil
+	}
+}
+
+// QueuedJobIDs returns up to limit job IDs from the consumer end of the list.
+func (r *Redis) QueuedJobIDs(ctx context.Context, queueName string, limit int64) ([]string, error) {
+	return r.Client.LRange(ctx, queueName, -limit, -1).Result()
+}
+
+// Remove deletes every occurrence of jobID from the list.
+func (r *Redis) Remove(ctx context.Context, queueName string, jobID string) error {
+	return r.Client.LRem(ctx, queueName, 0, jobID).Err()
+}
@@ -12,8 +12,17 @@ type FailureClass string
 const (
 	ClassRetryable FailureClass = "retryable"
 	ClassTerminal  FailureClass = "terminal"
+	// ClassTimeout is a retryable failure caused by the job running past its
+	// execution timeout. It is kept apart so timeouts can be told from other
+	// transient errors.
+	ClassTimeout FailureClass = "timeout"
 )
 
+// IsRetryable reports whether failures of class c are retried.
+func (c FailureClass) IsRetryable() bool {
+	return c != ClassTerminal
+}
+
 type retryableError struct {
 	err error
 }
@@ -28,6 +37,13 @@ type terminalError struct {
 func (e terminalError) Error() string { return e.err.Error() }
 func (e terminalError) Unwrap() error { return e.err }
 
+type timeoutError struct {
+	err error
+}
+
+func (e timeoutError) Error() string { return e.err.Error() }
+func (e timeoutError) Unwrap() error { return e.err }
+
 func Retryable(err error) error {
 	if err == nil {
 		return nil
@@ -42,6 +58,14 @@ func Terminal(err error) error {
 	return terminalError{err: err}
 }
 
+// Timeout marks err as the job exceeding its execution timeout.
+func Timeout(err error) error {
+	if err == nil {
+		return nil
+	}
+	return timeoutError{err: err}
+}
+
 func ClassifyError(err error) FailureClass {
 	if err == nil {
 		return ClassTerminal
@@ -55,6 +79,10 @@ func ClassifyError(err error) FailureClass {
 	if errors.As(err, &explicitTerminal) {
 		return ClassTerminal
 	}
+	var timeout timeoutError
+	if errors.As(err, &timeout) {
+		return ClassTimeout
+	}
 
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return ClassRetryable
@@ -24,6 +24,17 @@ func TestClassifyErrorExplicitTerminal(t *testing.T) {
 	}
 }
 
+func TestClassifyErrorTimeout(t *testing.T) {
+	err := Timeout(fmt.Errorf("job timed out after 30s: %w", context.DeadlineExceeded))
+	got := ClassifyError(err)
+	if got != ClassTimeout {
+		t.Fatalf("expected timeout, got %s", got)
+	}
+	if !got.IsRetryable() || ClassTerminal.IsRetryable() {
+		t.Fatal("expected timeouts to be retryable and terminal failures not")
+	}
+}
+
 func TestClassifyErrorKnownRetryableFailures(t *testing.T) {
 	cases := []error{
 		context.Canceled,
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pranavko12/taskforge/internal/metrics"
+	"github.com/pranavko12/taskforge/internal/retry"
+)
+
+// OutboxEntry is a queue publish committed alongside a job change.
+type OutboxEntry struct {
+	ID        int64
+	JobID     string
+	QueueName string
+	Attempts  int
+}
+
+type OutboxStore interface {
+	// ClaimOutbox returns unpublished entries created before createdBefore
+	// whose next attempt is due, and pushes their next attempt out by
+	// claimFor so concurrent relays do not publish the same entry.
+	ClaimOutbox(ctx context.Context, now time.Time, createdBefore time.Time, claimFor time.Duration, limit int) ([]OutboxEntry, error)
+	MarkOutboxDone(ctx context.Context, id int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+	// PurgeOutbox deletes entries published before the given time.
+	PurgeOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OutboxRelay publishes outbox entries that the API or scheduler could not
+// publish directly, retrying failed publishes with backoff. Publishing twice
+// is harmless because workers lease from Postgres, so the relay only waits a
+// short grace period before taking over an entry.
+type OutboxRelay struct {
+	store     OutboxStore
+	queue     Queue
+	limit     int
+	grace     time.Duration
+	claimFor  time.Duration
+	retention time.Duration
+	policy    retry.Policy
+}
+
+func NewOutboxRelay(store OutboxStore, queue Queue) *OutboxRelay {
+	return &OutboxRelay{
+		store:     store,
+		queue:     queue,
+		limit:     100,
+		grace:     5 * time.Second,
+		claimFor:  30 * time.Second,
+		retention: time.Hour,
+		policy: retry.Policy{
+			InitialDelay: time.Second,
+			Backoff:      2,
+			MaxDelay:     time.Minute,
+		},
+	}
+}
+
+// Relay publishes one batch of due entries and returns how many were
+// published. Without a queue, entries are marked done since there is
+// nothing to publish to.
+func (r *OutboxRelay) Relay(ctx context.Context, now time.Time) (int, error) {
+	entries, err := r.store.ClaimOutbox(ctx, now, now.Add(-r.grace), r.claimFor, r.limit)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	var errs []error
+	for _, entry := range entries {
+		if r.queue != nil {
+			if err := r.queue.Enqueue(ctx, entry.QueueName, entry.JobID); err != nil {
+				metrics.IncOutboxRelayFailures(entry.QueueName)
+				next := retry.NextRunAt(now, entry.Attempts+1, r.policy)
+				if err := r.store.MarkOutboxFailed(ctx, entry.ID, next, err.Error()); err != nil {
+					errs = append(errs, fmt.Errorf("outbox %d: %w", entry.ID, err))
+				}
+				continue
+			}
+			metrics.IncOutboxRelayed(entry.QueueName)
+		}
+		if err := r.store.MarkOutboxDone(ctx, entry.ID); err != nil {
+			errs = append(errs, fmt.Errorf("outbox %d: %w", entry.ID, err))
+			continue
+		}
+		published++
+	}
+
+	if _, err := r.store.PurgeOutbox(ctx, now.Add(-r.retention)); err != nil {
+		errs = append(errs, fmt.Errorf("purge outbox: %w", err))
+	}
+	return published, errors.Join(errs...)
+}
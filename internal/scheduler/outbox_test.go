@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOutboxRelayPublishesAndRetries(t *testing.T) {
+	now := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+	store := &fakeOutboxStore{entries: []OutboxEntry{
+		{ID: 1, JobID: "job-1", QueueName: "jobs:ready"},
+		{ID: 2, JobID: "job-2", QueueName: "critical", Attempts: 2},
+	}}
+	q := &fakeQueue{err: errors.New("redis down")}
+	r := NewOutboxRelay(store, q)
+
+	n, err := r.Relay(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Relay error: %v", err)
+	}
+	if n != 0 || len(store.done) != 0 {
+		t.Fatalf("expected nothing published while redis is down, got n=%d done=%v", n, store.done)
+	}
+	if got := store.failed[1].Sub(now); got != time.Second {
+		t.Fatalf("expected first retry after 1s, got %v", got)
+	}
+	if got := store.failed[2].Sub(now); got != 4*time.Second {
+		t.Fatalf("expected third retry after 4s, got %v", got)
+	}
+	if !store.createdBefore.Equal(now.Add(-r.grace)) {
+		t.Fatalf("expected claim to skip entries younger than the grace period, got %v", store.createdBefore)
+	}
+
+	q.err = nil
+	n, err = r.Relay(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Relay error: %v", err)
+	}
+	if n != 2 || len(store.done) != 2 {
+		t.Fatalf("expected 2 published, got n=%d done=%v", n, store.done)
+	}
+	if q.queues[0] != "jobs:ready" || q.queues[1] != "critical" {
+		t.Fatalf("expected entries published to their queues, got %v", q.queues)
+	}
+	if !store.purgedBefore.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("expected published entries purged after an hour, got %v", store.purgedBefore)
+	}
+}
+
+func TestOutboxRelayWithoutQueueMarksDone(t *testing.T) {
+	store := &fakeOutboxStore{entries: []OutboxEntry{{ID: 1, JobID: "job-1", QueueName: "jobs:ready"}}}
+	r := NewOutboxRelay(store, nil)
+
+	n, err := r.Relay(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Relay error: %v", err)
+	}
+	if n != 1 || len(store.done) != 1 {
+		t.Fatalf("expected entry marked done without a queue, got n=%d done=%v", n, store.done)
+	}
+}
+
+type fakeOutboxStore struct {
+	entries       []OutboxEntry
+	done          []int64
+	failed        map[int64]time.Time
+	createdBefore time.Time
+	purgedBefore  time.Time
+}
+
+func (f *fakeOutboxStore) ClaimOutbox(ctx context.Context, now time.Time, createdBefore time.Time, claimFor time.Duration, limit int) ([]OutboxEntry, error) {
+	f.createdBefore = createdBefore
+	var out []OutboxEntry
+	for _, entry := range f.entries {
+		published := false
+		for _, id := range f.done {
+			published = published || id == entry.ID
+		}
+		if !published {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeOutboxStore) MarkOutboxDone(ctx context.Context, id int64) error {
+	f.done = append(f.done, id)
+	return nil
+}
+
+func (f *fakeOutboxStore) MarkOutboxFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	if f.failed == nil {
+		f.failed = make(map[int64]time.Time)
+	}
+	f.failed[id] = nextAttemptAt
+	return nil
+}
+
+func (f *fakeOutboxStore) PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	f.purgedBefore = before
+	return 0, nil
+}
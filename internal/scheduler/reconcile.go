@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type ReconcileStore interface {
+	// ListReadyJobIDs returns PENDING jobs in queueName whose next_run_at has
+	// passed, oldest first.
+	ListReadyJobIDs(ctx context.Context, queueName string, limit int) ([]string, error)
+	// FilterPendingJobIDs returns the subset of jobIDs that are still PENDING.
+	FilterPendingJobIDs(ctx context.Context, jobIDs []string) ([]string, error)
+}
+
+// ListQueue is a Queue whose contents can be inspected and pruned.
+type ListQueue interface {
+	Queue
+	QueuedJobIDs(ctx context.Context, queueName string, limit int64) ([]string, error)
+	Remove(ctx context.Context, queueName string, jobID string) error
+}
+
+// QueueReconciler repairs drift between the Redis lists that hint at ready
+// jobs and the jobs table: ready jobs missing from a list are pushed again, and entries
+// for jobs that are no longer PENDING, or that are not job IDs at all, are
+// removed. Postgres stays
+// authoritative, so drift only costs latency or spurious wakeups.
+type QueueReconciler struct {
+	store  ReconcileStore
+	queue  ListQueue
+	queues []string
+	limit  int
+}
+
+func NewQueueReconciler(store ReconcileStore, queue ListQueue, queues []string) *QueueReconciler {
+	return &QueueReconciler{
+		store:  store,
+		queue:  queue,
+		queues: queues,
+		limit:  1000,
+	}
+}
+
+// Reconcile checks every queue once and returns how many IDs it pushed and
+// removed.
+func (r *QueueReconciler) Reconcile(ctx context.Context) (pushed int, removed int, err error) {
+	var errs []error
+	for _, queueName := range r.queues {
+		p, rm, err := r.reconcileQueue(ctx, queueName)
+		pushed += p
+		removed += rm
+		if err != nil {
+			errs = append(errs, fmt.Errorf("queue %s: %w", queueName, err))
+		}
+	}
+	return pushed, removed, errors.Join(errs...)
+}
+
+func (r *QueueReconciler) reconcileQueue(ctx context.Context, queueName string) (int, int, error) {
+	queued, err := r.queue.QueuedJobIDs(ctx, queueName, int64(r.limit))
+	if err != nil {
+		return 0, 0, err
+	}
+	inList := make(map[string]bool, len(queued))
+	for _, id := range queued {
+		inList[id] = true
+	}
+
+	removed := 0
+	if len(inList) > 0 {
+		ids := make([]string, 0, len(inList))
+		valid := make([]string, 0, len(inList))
+		for id := range inList {
+			ids = append(ids, id)
+			// An entry that is not a job ID cannot be PENDING; keep it out of
+			// the query so it is removed like any other stale entry.
+			if _, err := uuid.Parse(id); err == nil {
+				valid = append(valid, id)
+			}
+		}
+		stillPending := make(map[string]bool, len(valid))
+		if len(valid) > 0 {
+			pending, err := r.store.FilterPendingJobIDs(ctx, valid)
+			if err != nil {
+				return 0, 0, err
+			}
+			for _, id := range pending {
+				stillPending[id] = true
+			}
+		}
+		for _, id := range ids {
+			if stillPending[id] {
+				continue
+			}
+			if err := r.queue.Remove(ctx, queueName, id); err != nil {
+				return 0, removed, err
+			}
+			removed++
+		}
+	}
+
+	ready, err := r.store.ListReadyJobIDs(ctx, queueName, r.limit)
+	if err != nil {
+		return 0, removed, err
+	}
+	pushed := 0
+	for _, id := range ready {
+		if inList[id] {
+			continue
+		}
+		if err := r.queue.Enqueue(ctx, queueName, id); err != nil {
+			return pushed, removed, err
+		}
+		pushed++
+	}
+	return pushed, removed, nil
+}
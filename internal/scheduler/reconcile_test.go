@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+const (
+	job1       = "00000000-0000-0000-0000-000000000001"
+	job2       = "00000000-0000-0000-0000-000000000002"
+	jobDelayed = "00000000-0000-0000-0000-000000000003"
+	jobDone    = "00000000-0000-0000-0000-000000000004"
+)
+
+func TestQueueReconcilerRepairsDrift(t *testing.T) {
+	store := &fakeReconcileStore{
+		ready:   map[string][]string{"jobs:ready": {job1, job2}},
+		pending: map[string]bool{job1: true, job2: true, jobDelayed: true},
+	}
+	q := &fakeListQueue{lists: map[string][]string{
+		// job-2 lost its wakeup, job-done finished without popping its entry
+		// and job-delayed is pending but not due yet.
+		"jobs:ready": {job1, jobDone, jobDelayed},
+	}}
+	r := NewQueueReconciler(store, q, []string{"jobs:ready"})
+
+	pushed, removed, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile error: %v", err)
+	}
+	if pushed != 1 || removed != 1 {
+		t.Fatalf("expected 1 pushed and 1 removed, got %d and %d", pushed, removed)
+	}
+	got := append([]string(nil), q.lists["jobs:ready"]...)
+	sort.Strings(got)
+	want := []string{job1, job2, jobDelayed}
+	for i := range want {
+		if len(got) != len(want) || got[i] != want[i] {
+			t.Fatalf("expected list %v, got %v", want, got)
+		}
+	}
+
+	pushed, removed, err = r.Reconcile(context.Background())
+	if err != nil || pushed != 0 || removed != 0 {
+		t.Fatalf("expected second pass to be a no-op, got pushed=%d removed=%d err=%v", pushed, removed, err)
+	}
+}
+
+func TestQueueReconcilerRemovesEntriesThatAreNotJobIDs(t *testing.T) {
+	store := &fakeReconcileStore{
+		ready:   map[string][]string{"jobs:ready": {job1}},
+		pending: map[string]bool{job1: true},
+	}
+	q := &fakeListQueue{lists: map[string][]string{"jobs:ready": {job1, "not-a-job-id"}}}
+	r := NewQueueReconciler(store, q, []string{"jobs:ready"})
+
+	pushed, removed, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile error: %v", err)
+	}
+	if pushed != 0 || removed != 1 {
+		t.Fatalf("expected the junk entry removed, got pushed=%d removed=%d", pushed, removed)
+	}
+	if got := q.lists["jobs:ready"]; len(got) != 1 || got[0] != job1 {
+		t.Fatalf("expected only %s left, got %v", job1, got)
+	}
+	for _, id := range store.queried {
+		if id == "not-a-job-id" {
+			t.Fatalf("expected the junk entry kept out of the query, got %v", store.queried)
+		}
+	}
+}
+
+type fakeReconcileStore struct {
+	ready   map[string][]string
+	pending map[string]bool
+	queried []string
+}
+
+func (f *fakeReconcileStore) ListReadyJobIDs(ctx context.Context, queueName string, limit int) ([]string, error) {
+	return f.ready[queueName], nil
+}
+
+func (f *fakeReconcileStore) FilterPendingJobIDs(ctx context.Context, jobIDs []string) ([]string, error) {
+	f.queried = append(f.queried, jobIDs...)
+	var out []string
+	for _, id := range jobIDs {
+		if f.pending[id] {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+type fakeListQueue struct {
+	lists map[string][]string
+}
+
+func (f *fakeListQueue) Enqueue(ctx context.Context, queueName string, jobID string) error {
+	f.lists[queueName] = append([]string{jobID}, f.lists[queueName]...)
+	return nil
+}
+
+func (f *fakeListQueue) QueuedJobIDs(ctx context.Context, queueName string, limit int64) ([]string, error) {
+	return append([]string(nil), f.lists[queueName]...), nil
+}
+
+func (f *fakeListQueue) Remove(ctx context.Context, queueName string, jobID string) error {
+	var kept []string
+	for _, id := range f.lists[queueName] {
+		if id != jobID {
+			kept = append(kept, id)
+		}
+	}
+	f.lists[queueName] = kept
+	return nil
+}
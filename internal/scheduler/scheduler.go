@@ -3,8 +3,11 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/pranavko12/taskforge/internal/metrics"
 	"github.com/pranavko12/taskforge/internal/retry"
 	"github.com/pranavko12/taskforge/internal/telemetry"
 	"go.opentelemetry.io/otel"
@@ -14,8 +17,23 @@ import (
 
 var ErrMaxAttemptsExceeded = errors.New("max attempts exceeded")
 
+// ErrNotFailed is returned by ScheduleRetry when the job left FAILED, e.g. it
+// was canceled, before the scheduler could act on it.
+var ErrNotFailed = errors.New("job is no longer FAILED")
+
+// ErrInvalidRetryPolicy is returned by ScheduleRetry when the job's stored
+// retry policy does not validate. The job is moved to the DLQ with
+// ReasonInvalidRetryPolicy rather than left FAILED, where it would be listed
+// again on every pass.
+var ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+// ReasonInvalidRetryPolicy is the dead-letter reason of a job whose retry
+// policy does not validate.
+const ReasonInvalidRetryPolicy = "invalid_retry_policy"
+
 type RetryJob struct {
 	JobID        string
+	QueueName    string
 	RetryCount   int
 	MaxAttempts  int
 	InitialDelay int
@@ -25,18 +43,61 @@ type RetryJob struct {
 	Traceparent  string
 }
 
+// FailedJob is a FAILED job awaiting its retry decision, with the queue it
+// belongs to.
+type FailedJob struct {
+	JobID     string
+	QueueName string
+}
+
+// DueRetry is a RETRYING job whose backoff has elapsed, with the queue it
+// must be re-enqueued to.
+type DueRetry struct {
+	JobID     string
+	QueueName string
+}
+
 type Store interface {
 	GetRetryJob(ctx context.Context, jobID string) (RetryJob, error)
-	UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) error
-	ListDueRetries(ctx context.Context, now time.Time, limit int) ([]string, error)
+	// UpdateRetrySchedule moves a FAILED job to RETRYING. It reports false if
+	// the job was no longer FAILED.
+	UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) (bool, error)
+	ListFailedJobs(ctx context.Context, limit int) ([]FailedJob, error)
+	ListDueRetries(ctx context.Context, now time.Time, limit int) ([]DueRetry, error)
+	// MarkRetryEnqueued makes the job PENDING and writes its outbox entry in
+	// the same transaction.
 	MarkRetryEnqueued(ctx context.Context, jobID string) error
-	MarkTerminalFailure(ctx context.Context, jobID string, reason string) error
+	// MarkTerminalFailure moves a FAILED job to the DLQ. It reports false if
+	// the job was no longer FAILED.
+	MarkTerminalFailure(ctx context.Context, jobID string, reason string) (bool, error)
+	PublishMarker
+}
+
+// PublishMarker records that a job's pending outbox entries were published
+// directly, so the OutboxRelay skips them.
+type PublishMarker interface {
+	MarkJobPublished(ctx context.Context, jobID string)
 }
 
+// Queue publishes job IDs to Redis. It may be nil when the deployment runs
+// in Postgres-only mode.
 type Queue interface {
 	Enqueue(ctx context.Context, queueName string, jobID string) error
 }
 
+// publish pushes a job whose outbox entry is already committed. A failed
+// publish is left to the OutboxRelay, so it is logged rather than returned.
+func publish(ctx context.Context, queue Queue, marker PublishMarker, queueName string, jobID string) {
+	if queue == nil {
+		return
+	}
+	if err := queue.Enqueue(ctx, queueName, jobID); err != nil {
+		slog.Warn("publish job failed, leaving it to the outbox relay", "job_id", jobID, "queue", queueName, "err", err)
+		return
+	}
+	marker.MarkJobPublished(ctx, jobID)
+}
+
 type Scheduler struct {
 	store     Store
 	queue     Queue
@@ -67,16 +128,20 @@ func (s *Scheduler) ScheduleRetry(ctx context.Context, jobID string, now time.Ti
 	_, span := tracer.Start(spanCtx, "schedule_retry",
 		trace.WithAttributes(
 			attribute.String("job_id", jobID),
-			attribute.String("queue", s.queueName),
+			attribute.String("queue", s.jobQueue(job.QueueName)),
 		),
 	)
 	defer span.End()
 
 	nextRetryCount := job.RetryCount + 1
 	if job.MaxAttempts > 0 && nextRetryCount >= job.MaxAttempts {
-		if err := s.store.MarkTerminalFailure(ctx, jobID, "max attempts exceeded"); err != nil {
+		ok, err := s.store.MarkTerminalFailure(ctx, jobID, "max attempts exceeded")
+		if err != nil {
 			return time.Time{}, err
 		}
+		if !ok {
+			return time.Time{}, ErrNotFailed
+		}
 		return time.Time{}, ErrMaxAttemptsExceeded
 	}
 
@@ -88,29 +153,77 @@ func (s *Scheduler) ScheduleRetry(ctx context.Context, jobID string, now time.Ti
 		Jitter:       job.Jitter,
 	}
 	if err := policy.Validate(); err != nil {
-		return time.Time{}, err
+		ok, markErr := s.store.MarkTerminalFailure(ctx, jobID, ReasonInvalidRetryPolicy)
+		if markErr != nil {
+			return time.Time{}, markErr
+		}
+		if !ok {
+			return time.Time{}, ErrNotFailed
+		}
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidRetryPolicy, err)
 	}
 
 	nextRunAt := retry.NextRunAt(now, nextRetryCount, policy)
-	if err := s.store.UpdateRetrySchedule(ctx, jobID, nextRetryCount, nextRunAt); err != nil {
+	ok, err := s.store.UpdateRetrySchedule(ctx, jobID, nextRetryCount, nextRunAt)
+	if err != nil {
 		return time.Time{}, err
 	}
+	if !ok {
+		return time.Time{}, ErrNotFailed
+	}
 	return nextRunAt, nil
 }
 
-// EnqueueDueRetries requeues retryable jobs whose next_run_at has passed.
-func (s *Scheduler) EnqueueDueRetries(ctx context.Context, now time.Time) (int, error) {
-	ids, err := s.store.ListDueRetries(ctx, now, s.limit)
+// RetryFailedJobs applies the retry policy to jobs the worker left in FAILED:
+// each one is either scheduled for RETRYING with backoff or, once its attempts
+// are exhausted or its policy is invalid, moved to the DLQ. A job that cannot
+// be handled does not hold up the others; the errors are returned together.
+func (s *Scheduler) RetryFailedJobs(ctx context.Context, now time.Time) (int, error) {
+	failed, err := s.store.ListFailedJobs(ctx, s.limit)
 	if err != nil {
 		return 0, err
 	}
-	for _, id := range ids {
-		if err := s.queue.Enqueue(ctx, s.queueName, id); err != nil {
-			return 0, err
+	var errs []error
+	for _, job := range failed {
+		queueName := s.jobQueue(job.QueueName)
+		_, err := s.ScheduleRetry(ctx, job.JobID, now, 0)
+		switch {
+		case err == nil:
+			metrics.IncRetriesScheduled(queueName)
+		case errors.Is(err, ErrMaxAttemptsExceeded):
+			metrics.IncRetriesExhausted(queueName)
+		case errors.Is(err, ErrInvalidRetryPolicy):
+			slog.Warn("dead-lettered job with invalid retry policy", "job_id", job.JobID, "queue", queueName, "err", err)
+		case errors.Is(err, ErrNotFailed):
+		default:
+			errs = append(errs, fmt.Errorf("job %s: %w", job.JobID, err))
 		}
-		if err := s.store.MarkRetryEnqueued(ctx, id); err != nil {
+	}
+	return len(failed), errors.Join(errs...)
+}
+
+// jobQueue is queueName, or the scheduler's default queue for jobs stored
+// without one.
+func (s *Scheduler) jobQueue(queueName string) string {
+	if queueName == "" {
+		return s.queueName
+	}
+	return queueName
+}
+
+// EnqueueDueRetries requeues retryable jobs whose next_run_at has passed onto
+// the queue each job was submitted to.
+func (s *Scheduler) EnqueueDueRetries(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.store.ListDueRetries(ctx, now, s.limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range due {
+		queueName := s.jobQueue(job.QueueName)
+		if err := s.store.MarkRetryEnqueued(ctx, job.JobID); err != nil {
 			return 0, err
 		}
+		publish(ctx, s.queue, s.store, queueName, job.JobID)
 	}
-	return len(ids), nil
+	return len(due), nil
 }
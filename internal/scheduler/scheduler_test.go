@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -75,8 +76,57 @@ func TestScheduleRetryStopsAtMaxAttempts(t *testing.T) {
 	}
 }
 
+func TestScheduleRetrySkipsJobThatLeftFailed(t *testing.T) {
+	store := &fakeStore{
+		job:       RetryJob{JobID: "job-1", RetryCount: 2, MaxAttempts: 3},
+		notFailed: true,
+	}
+	s := New(store, &fakeQueue{}, "jobs:ready")
+	if _, err := s.ScheduleRetry(context.Background(), "job-1", time.Now(), 1); !errors.Is(err, ErrNotFailed) {
+		t.Fatalf("expected ErrNotFailed for a job canceled meanwhile, got %v", err)
+	}
+
+	// The same when the job still has attempts left.
+	store.job = RetryJob{JobID: "job-1", MaxAttempts: 5, InitialDelay: 1000, Backoff: 2, MaxDelay: 60000}
+	if _, err := s.ScheduleRetry(context.Background(), "job-1", time.Now(), 1); !errors.Is(err, ErrNotFailed) {
+		t.Fatalf("expected ErrNotFailed when no row was rescheduled, got %v", err)
+	}
+	if len(store.scheduled) != 0 {
+		t.Fatalf("expected nothing scheduled, got %v", store.scheduled)
+	}
+}
+
+func TestRetryFailedJobsContinuesPastBadJob(t *testing.T) {
+	good := RetryJob{MaxAttempts: 5, InitialDelay: 1000, Backoff: 2, MaxDelay: 60000}
+	store := &fakeStore{
+		failed: []FailedJob{
+			{JobID: "bad", QueueName: "critical"},
+			{JobID: "invalid", QueueName: "critical"},
+			{JobID: "good", QueueName: "critical"},
+		},
+		jobs: map[string]RetryJob{
+			// A stored policy that no longer validates.
+			"invalid": {JobID: "invalid", MaxAttempts: 5, InitialDelay: 1000, Backoff: 0, MaxDelay: 60000},
+			"good":    good,
+		},
+		getErrs: map[string]error{"bad": errors.New("connection reset")},
+	}
+	s := New(store, &fakeQueue{}, "jobs:ready")
+
+	n, err := s.RetryFailedJobs(context.Background(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "job bad") || strings.Contains(err.Error(), "job invalid") {
+		t.Fatalf("expected only the bad job's error, got %v", err)
+	}
+	if n != 3 || len(store.scheduled) != 1 || store.scheduled[0] != "good" {
+		t.Fatalf("expected the good job scheduled despite the bad one, got n=%d scheduled=%v", n, store.scheduled)
+	}
+	if len(store.terminated) != 1 || store.terminated[0] != "invalid" || store.terminalReason != ReasonInvalidRetryPolicy {
+		t.Fatalf("expected the invalid policy dead-lettered, got %v reason %q", store.terminated, store.terminalReason)
+	}
+}
+
 func TestEnqueueDueRetries(t *testing.T) {
-	store := &fakeStore{due: []string{"job-1", "job-2"}}
+	store := &fakeStore{due: []DueRetry{{JobID: "job-1"}, {JobID: "job-2", QueueName: "critical"}}}
 	q := &fakeQueue{}
 	s := New(store, q, "jobs:ready")
 
@@ -93,29 +143,147 @@ func TestEnqueueDueRetries(t *testing.T) {
 	if len(store.marked) != 2 {
 		t.Fatalf("expected 2 marked, got %d", len(store.marked))
 	}
+	if q.queues[0] != "jobs:ready" || q.queues[1] != "critical" {
+		t.Fatalf("expected retries on their own queues, got %v", q.queues)
+	}
+	if len(store.published) != 2 {
+		t.Fatalf("expected outbox entries marked published, got %v", store.published)
+	}
+}
+
+func TestEnqueueDueRetriesLeavesFailedPublishToOutbox(t *testing.T) {
+	store := &fakeStore{due: []DueRetry{{JobID: "job-1"}}}
+	s := New(store, &fakeQueue{err: errors.New("redis down")}, "jobs:ready")
+
+	n, err := s.EnqueueDueRetries(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("EnqueueDueRetries error: %v", err)
+	}
+	if n != 1 || len(store.marked) != 1 {
+		t.Fatalf("expected job marked PENDING despite publish failure, got n=%d marked=%v", n, store.marked)
+	}
+	if len(store.published) != 0 {
+		t.Fatalf("expected outbox entry left for the relay, got %v", store.published)
+	}
+}
+
+func TestEnqueueDueRetriesWithoutQueue(t *testing.T) {
+	store := &fakeStore{due: []DueRetry{{JobID: "job-1"}}}
+	s := New(store, nil, "jobs:ready")
+
+	n, err := s.EnqueueDueRetries(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("EnqueueDueRetries error: %v", err)
+	}
+	if n != 1 || len(store.marked) != 1 {
+		t.Fatalf("expected job marked PENDING without a queue, got n=%d marked=%v", n, store.marked)
+	}
+}
+
+func TestRetryFailedJobsFullCycleEndsInDLQ(t *testing.T) {
+	now := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+	store := &cycleStore{
+		state: "PENDING",
+		job: cycleJob{RetryJob: RetryJob{
+			JobID:        "job-1",
+			MaxAttempts:  3,
+			InitialDelay: 1000,
+			Backoff:      2,
+			MaxDelay:     60000,
+		}},
+	}
+	q := &fakeQueue{}
+	s := New(store, q, "jobs:ready")
+
+	wantDelays := []time.Duration{1 * time.Second, 2 * time.Second}
+	for attempt := 1; attempt <= 3; attempt++ {
+		// Worker leases the job and fails it with a retryable error.
+		if store.state != "PENDING" {
+			t.Fatalf("attempt %d: expected PENDING before run, got %s", attempt, store.state)
+		}
+		store.state = "FAILED"
+
+		n, err := s.RetryFailedJobs(context.Background(), now)
+		if err != nil {
+			t.Fatalf("attempt %d: RetryFailedJobs error: %v", attempt, err)
+		}
+		if n != 1 {
+			t.Fatalf("attempt %d: expected 1 processed, got %d", attempt, n)
+		}
+		if attempt == 3 {
+			break
+		}
+
+		if store.state != "RETRYING" {
+			t.Fatalf("attempt %d: expected RETRYING, got %s", attempt, store.state)
+		}
+		if got := store.job.NextRunAt.Sub(now); got != wantDelays[attempt-1] {
+			t.Fatalf("attempt %d: expected backoff %v, got %v", attempt, wantDelays[attempt-1], got)
+		}
+
+		// Not due yet.
+		if n, _ := s.EnqueueDueRetries(context.Background(), now); n != 0 {
+			t.Fatalf("attempt %d: expected nothing due before backoff, got %d", attempt, n)
+		}
+		now = store.job.NextRunAt
+		if n, _ := s.EnqueueDueRetries(context.Background(), now); n != 1 {
+			t.Fatalf("attempt %d: expected job re-enqueued after backoff, got %d", attempt, n)
+		}
+	}
+
+	if store.state != "DLQ" {
+		t.Fatalf("expected DLQ after max attempts, got %s", store.state)
+	}
+	if store.terminalReason != "max attempts exceeded" {
+		t.Fatalf("unexpected dlq reason %q", store.terminalReason)
+	}
+	if len(q.enqueued) != 2 {
+		t.Fatalf("expected 2 reruns, got %d", len(q.enqueued))
+	}
 }
 
 type fakeStore struct {
 	job              RetryJob
+	jobs             map[string]RetryJob
+	getErrs          map[string]error
 	updateRetryCount int
 	updateNextRunAt  time.Time
-	due              []string
+	due              []DueRetry
+	failed           []FailedJob
 	marked           []string
+	published        []string
 	terminalCalled   bool
 	terminalReason   string
+	terminated       []string
+	notFailed        bool
+	scheduled        []string
 }
 
 func (f *fakeStore) GetRetryJob(ctx context.Context, jobID string) (RetryJob, error) {
+	if err := f.getErrs[jobID]; err != nil {
+		return RetryJob{}, err
+	}
+	if job, ok := f.jobs[jobID]; ok {
+		return job, nil
+	}
 	return f.job, nil
 }
 
-func (f *fakeStore) UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) error {
+func (f *fakeStore) UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) (bool, error) {
+	if f.notFailed {
+		return false, nil
+	}
 	f.updateRetryCount = retryCount
 	f.updateNextRunAt = nextRunAt
-	return nil
+	f.scheduled = append(f.scheduled, jobID)
+	return true, nil
 }
 
-func (f *fakeStore) ListDueRetries(ctx context.Context, now time.Time, limit int) ([]string, error) {
+func (f *fakeStore) ListFailedJobs(ctx context.Context, limit int) ([]FailedJob, error) {
+	return f.failed, nil
+}
+
+func (f *fakeStore) ListDueRetries(ctx context.Context, now time.Time, limit int) ([]DueRetry, error) {
 	return f.due, nil
 }
 
@@ -124,17 +292,81 @@ func (f *fakeStore) MarkRetryEnqueued(ctx context.Context, jobID string) error {
 	return nil
 }
 
-func (f *fakeStore) MarkTerminalFailure(ctx context.Context, jobID string, reason string) error {
+func (f *fakeStore) MarkJobPublished(ctx context.Context, jobID string) {
+	f.published = append(f.published, jobID)
+}
+
+func (f *fakeStore) MarkTerminalFailure(ctx context.Context, jobID string, reason string) (bool, error) {
 	f.terminalCalled = true
 	f.terminalReason = reason
+	if f.notFailed {
+		return false, nil
+	}
+	f.terminated = append(f.terminated, jobID)
+	return true, nil
+}
+
+// cycleStore tracks a single job through FAILED -> RETRYING -> PENDING -> DLQ.
+type cycleStore struct {
+	job            cycleJob
+	state          string
+	terminalReason string
+}
+
+type cycleJob struct {
+	RetryJob
+	NextRunAt time.Time
+}
+
+func (c *cycleStore) GetRetryJob(ctx context.Context, jobID string) (RetryJob, error) {
+	return c.job.RetryJob, nil
+}
+
+func (c *cycleStore) UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) (bool, error) {
+	c.job.RetryCount = retryCount
+	c.job.NextRunAt = nextRunAt
+	c.state = "RETRYING"
+	return true, nil
+}
+
+func (c *cycleStore) ListFailedJobs(ctx context.Context, limit int) ([]FailedJob, error) {
+	if c.state != "FAILED" {
+		return nil, nil
+	}
+	return []FailedJob{{JobID: c.job.JobID}}, nil
+}
+
+func (c *cycleStore) ListDueRetries(ctx context.Context, now time.Time, limit int) ([]DueRetry, error) {
+	if c.state != "RETRYING" || c.job.NextRunAt.After(now) {
+		return nil, nil
+	}
+	return []DueRetry{{JobID: c.job.JobID}}, nil
+}
+
+func (c *cycleStore) MarkRetryEnqueued(ctx context.Context, jobID string) error {
+	c.state = "PENDING"
 	return nil
 }
 
+func (c *cycleStore) MarkJobPublished(ctx context.Context, jobID string) {}
+
+func (c *cycleStore) MarkTerminalFailure(ctx context.Context, jobID string, reason string) (bool, error) {
+	c.state = "DLQ"
+	c.terminalReason = reason
+	return true, nil
+}
+
 type fakeQueue struct {
 	enqueued []string
+	queues   []string
+	err      error
 }
 
 func (f *fakeQueue) Enqueue(ctx context.Context, queueName string, jobID string) error {
+	if f.err != nil {
+		return f.err
+	}
 	f.enqueued = append(f.enqueued, jobID)
+	f.queues = append(f.queues, queueName)
 	return nil
 }
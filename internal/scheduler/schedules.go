@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pranavko12/taskforge/internal/cron"
+	"github.com/pranavko12/taskforge/internal/metrics"
+)
+
+const (
+	MissedRunSkip    = "skip"
+	MissedRunOnce    = "run_once"
+	MissedRunCatchUp = "catch_up"
+)
+
+// Schedule is a recurring schedule whose next fire time has passed.
+type Schedule struct {
+	ID              string
+	Cron            string
+	Timezone        string
+	QueueName       string
+	JobType         string
+	Payload         []byte
+	MissedRunPolicy string
+	Priority        int
+	MaxAttempts     int
+	InitialDelay    int
+	Backoff         float64
+	MaxDelay        int
+	Jitter          bool
+	NextFireAt      time.Time
+}
+
+type ScheduleStore interface {
+	ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]Schedule, error)
+	// InsertScheduledJob inserts the job for one fire time and reports false
+	// if a job with the same idempotency key already exists.
+	InsertScheduledJob(ctx context.Context, sched Schedule, jobID string, idempotencyKey string, fireAt time.Time) (bool, error)
+	// AdvanceSchedule moves next_fire_at forward only if it still equals
+	// expected, so concurrent schedulers advance a schedule once.
+	AdvanceSchedule(ctx context.Context, scheduleID string, expected, next time.Time, lastFireAt *time.Time) (bool, error)
+	PublishMarker
+}
+
+// CronMaterializer turns due schedule fire times into jobs.
+type CronMaterializer struct {
+	store      ScheduleStore
+	queue      Queue
+	limit      int
+	maxCatchUp int
+	skipGrace  time.Duration
+}
+
+func NewCronMaterializer(store ScheduleStore, queue Queue) *CronMaterializer {
+	return &CronMaterializer{
+		store:      store,
+		queue:      queue,
+		limit:      100,
+		maxCatchUp: 100,
+		skipGrace:  time.Minute,
+	}
+}
+
+// ScheduledRunKey is the idempotency key of the job materialized for a fire
+// time. The per-queue idempotency index turns a second insert into a no-op.
+func ScheduledRunKey(scheduleID string, fireAt time.Time) string {
+	return "schedule:" + scheduleID + ":" + fireAt.UTC().Format(time.RFC3339)
+}
+
+// MaterializeDueRuns inserts and enqueues jobs for every schedule whose next
+// fire time has passed, applying the schedule's missed-run policy when more
+// than one fire time is due. It returns the number of jobs created.
+func (m *CronMaterializer) MaterializeDueRuns(ctx context.Context, now time.Time) (int, error) {
+	due, err := m.store.ListDueSchedules(ctx, now, m.limit)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	var errs []error
+	for _, sched := range due {
+		n, err := m.materialize(ctx, sched, now)
+		created += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", sched.ID, err))
+		}
+	}
+	return created, errors.Join(errs...)
+}
+
+func (m *CronMaterializer) materialize(ctx context.Context, sched Schedule, now time.Time) (int, error) {
+	expr, err := cron.Parse(sched.Cron)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := time.LoadLocation(sched.Timezone)
+	if err != nil {
+		return 0, err
+	}
+
+	var fires []time.Time
+	next := sched.NextFireAt
+	for !next.IsZero() && !next.After(now) {
+		fires = append(fires, next)
+		next = expr.Next(next.In(loc))
+		// Catch-up resumes from next on the following pass.
+		if sched.MissedRunPolicy == MissedRunCatchUp && len(fires) == m.maxCatchUp {
+			break
+		}
+	}
+	if len(fires) == 0 {
+		return 0, nil
+	}
+	if next.IsZero() {
+		return 0, errors.New("cron expression has no further fire time")
+	}
+
+	runs := fires
+	switch sched.MissedRunPolicy {
+	case MissedRunCatchUp:
+	case MissedRunOnce:
+		runs = fires[len(fires)-1:]
+	default:
+		runs = nil
+		if latest := fires[len(fires)-1]; now.Sub(latest) <= m.skipGrace {
+			runs = fires[len(fires)-1:]
+		}
+	}
+	if missed := len(fires) - len(runs); missed > 0 {
+		metrics.AddScheduledRunsMissed(sched.QueueName, missed)
+	}
+
+	created := 0
+	for _, fireAt := range runs {
+		jobID := uuid.New().String()
+		inserted, err := m.store.InsertScheduledJob(ctx, sched, jobID, ScheduledRunKey(sched.ID, fireAt), fireAt)
+		if err != nil {
+			return created, err
+		}
+		if !inserted {
+			continue
+		}
+		created++
+		metrics.IncScheduledRuns(sched.QueueName)
+		publish(ctx, m.queue, m.store, sched.QueueName, jobID)
+	}
+
+	lastFireAt := fires[len(fires)-1]
+	if _, err := m.store.AdvanceSchedule(ctx, sched.ID, sched.NextFireAt, next, &lastFireAt); err != nil {
+		return created, err
+	}
+	return created, nil
+}
@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMaterializeDueRunsCreatesOneJobPerFireTime(t *testing.T) {
+	fireAt := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+	store := &fakeScheduleStore{due: []Schedule{{
+		ID:         "sched-1",
+		Cron:       "0 * * * *",
+		Timezone:   "UTC",
+		QueueName:  "jobs:ready",
+		JobType:    "report",
+		NextFireAt: fireAt,
+	}}}
+	q := &fakeQueue{}
+	m := NewCronMaterializer(store, q)
+
+	now := fireAt.Add(2 * time.Second)
+	created, err := m.MaterializeDueRuns(context.Background(), now)
+	if err != nil {
+		t.Fatalf("MaterializeDueRuns error: %v", err)
+	}
+	if created != 1 || len(q.enqueued) != 1 {
+		t.Fatalf("expected 1 job, got created=%d enqueued=%d", created, len(q.enqueued))
+	}
+	if want := "schedule:sched-1:2026-02-02T12:00:00Z"; store.keys[0] != want {
+		t.Fatalf("expected key %q, got %q", want, store.keys[0])
+	}
+	if want := fireAt.Add(time.Hour); !store.advancedTo.Equal(want) {
+		t.Fatalf("expected next fire %s, got %s", want, store.advancedTo)
+	}
+
+	// A second scheduler racing on the same fire time must not double fire.
+	store.due[0].NextFireAt = fireAt
+	created, err = m.MaterializeDueRuns(context.Background(), now)
+	if err != nil {
+		t.Fatalf("MaterializeDueRuns error: %v", err)
+	}
+	if created != 0 || len(q.enqueued) != 1 {
+		t.Fatalf("expected duplicate fire to be ignored, got created=%d enqueued=%d", created, len(q.enqueued))
+	}
+}
+
+func TestMaterializeDueRunsMissedRunPolicies(t *testing.T) {
+	lastFire := time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)
+	// The scheduler was down for three hourly fire times.
+	now := time.Date(2026, 2, 2, 11, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		policy string
+		want   []string
+	}{
+		{MissedRunSkip, nil},
+		{MissedRunOnce, []string{"schedule:s:2026-02-02T11:00:00Z"}},
+		{MissedRunCatchUp, []string{
+			"schedule:s:2026-02-02T09:00:00Z",
+			"schedule:s:2026-02-02T10:00:00Z",
+			"schedule:s:2026-02-02T11:00:00Z",
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.policy, func(t *testing.T) {
+			store := &fakeScheduleStore{due: []Schedule{{
+				ID:              "s",
+				Cron:            "@hourly",
+				Timezone:        "UTC",
+				QueueName:       "jobs:ready",
+				MissedRunPolicy: tc.policy,
+				NextFireAt:      lastFire,
+			}}}
+			m := NewCronMaterializer(store, &fakeQueue{})
+			if _, err := m.MaterializeDueRuns(context.Background(), now); err != nil {
+				t.Fatalf("MaterializeDueRuns error: %v", err)
+			}
+			if len(store.keys) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, store.keys)
+			}
+			for i := range tc.want {
+				if store.keys[i] != tc.want[i] {
+					t.Fatalf("expected %v, got %v", tc.want, store.keys)
+				}
+			}
+			if want := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC); !store.advancedTo.Equal(want) {
+				t.Fatalf("expected next fire %s, got %s", want, store.advancedTo)
+			}
+		})
+	}
+}
+
+func TestMaterializeDueRunsCatchUpIsCappedPerPass(t *testing.T) {
+	store := &fakeScheduleStore{due: []Schedule{{
+		ID:              "s",
+		Cron:            "* * * * *",
+		Timezone:        "UTC",
+		MissedRunPolicy: MissedRunCatchUp,
+		NextFireAt:      time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC),
+	}}}
+	m := NewCronMaterializer(store, &fakeQueue{})
+	m.maxCatchUp = 5
+
+	now := time.Date(2026, 2, 2, 10, 0, 0, 0, time.UTC)
+	created, err := m.MaterializeDueRuns(context.Background(), now)
+	if err != nil {
+		t.Fatalf("MaterializeDueRuns error: %v", err)
+	}
+	if created != 5 {
+		t.Fatalf("expected 5 jobs, got %d", created)
+	}
+	if want := time.Date(2026, 2, 2, 9, 5, 0, 0, time.UTC); !store.advancedTo.Equal(want) {
+		t.Fatalf("expected catch-up to resume at %s, got %s", want, store.advancedTo)
+	}
+}
+
+func TestMaterializeDueRunsUsesScheduleTimezone(t *testing.T) {
+	// 09:00 in New York is 14:00 UTC in winter.
+	fireAt := time.Date(2026, 2, 2, 14, 0, 0, 0, time.UTC)
+	store := &fakeScheduleStore{due: []Schedule{{
+		ID:         "s",
+		Cron:       "0 9 * * *",
+		Timezone:   "America/New_York",
+		NextFireAt: fireAt,
+	}}}
+	m := NewCronMaterializer(store, &fakeQueue{})
+	if _, err := m.MaterializeDueRuns(context.Background(), fireAt); err != nil {
+		t.Fatalf("MaterializeDueRuns error: %v", err)
+	}
+	if want := fireAt.Add(24 * time.Hour); !store.advancedTo.Equal(want) {
+		t.Fatalf("expected next fire %s, got %s", want, store.advancedTo)
+	}
+}
+
+type fakeScheduleStore struct {
+	due        []Schedule
+	seen       map[string]bool
+	keys       []string
+	advancedTo time.Time
+}
+
+func (f *fakeScheduleStore) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]Schedule, error) {
+	return f.due, nil
+}
+
+func (f *fakeScheduleStore) InsertScheduledJob(ctx context.Context, sched Schedule, jobID string, idempotencyKey string, fireAt time.Time) (bool, error) {
+	if f.seen == nil {
+		f.seen = make(map[string]bool)
+	}
+	if f.seen[idempotencyKey] {
+		return false, nil
+	}
+	f.seen[idempotencyKey] = true
+	f.keys = append(f.keys, idempotencyKey)
+	return true, nil
+}
+
+func (f *fakeScheduleStore) MarkJobPublished(ctx context.Context, jobID string) {}
+
+func (f *fakeScheduleStore) AdvanceSchedule(ctx context.Context, scheduleID string, expected, next time.Time, lastFireAt *time.Time) (bool, error) {
+	f.advancedTo = next
+	return true, nil
+}
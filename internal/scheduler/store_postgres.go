@@ -2,9 +2,13 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/pranavko12/taskforge/internal/storage"
 )
 
 type PostgresStore struct {
@@ -18,11 +22,12 @@ func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
 func (s *PostgresStore) GetRetryJob(ctx context.Context, jobID string) (RetryJob, error) {
 	var job RetryJob
 	err := s.pool.QueryRow(ctx, `
-		SELECT job_id, retry_count, max_attempts, initial_delay, backoff, max_delay, jitter, COALESCE(traceparent, '')
+		SELECT job_id, queue_name, retry_count, max_attempts, initial_delay, backoff, max_delay, jitter, COALESCE(traceparent, '')
 		FROM jobs
 		WHERE job_id = $1
 	`, jobID).Scan(
 		&job.JobID,
+		&job.QueueName,
 		&job.RetryCount,
 		&job.MaxAttempts,
 		&job.InitialDelay,
@@ -37,21 +42,67 @@ func (s *PostgresStore) GetRetryJob(ctx context.Context, jobID string) (RetryJob
 	return job, nil
 }
 
-func (s *PostgresStore) UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) error {
-	_, err := s.pool.Exec(ctx, `
+func (s *PostgresStore) UpdateRetrySchedule(ctx context.Context, jobID string, retryCount int, nextRunAt time.Time) (ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorScheduler, "retry scheduled"); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET retry_count = $1,
 			state = 'RETRYING',
 			next_run_at = $2,
 			updated_at = NOW()
-		WHERE job_id = $3
+		WHERE job_id = $3 AND state = 'FAILED'
 	`, retryCount, nextRunAt, jobID)
-	return err
+	if err != nil {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 }
 
-func (s *PostgresStore) ListDueRetries(ctx context.Context, now time.Time, limit int) ([]string, error) {
+func (s *PostgresStore) ListFailedJobs(ctx context.Context, limit int) ([]FailedJob, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT job_id
+		SELECT job_id, queue_name
+		FROM jobs
+		WHERE state = 'FAILED'
+		ORDER BY updated_at ASC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failed []FailedJob
+	for rows.Next() {
+		var job FailedJob
+		if err := rows.Scan(&job.JobID, &job.QueueName); err != nil {
+			return nil, err
+		}
+		failed = append(failed, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+func (s *PostgresStore) ListDueRetries(ctx context.Context, now time.Time, limit int) ([]DueRetry, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT job_id, queue_name
 		FROM jobs
 		WHERE state = 'RETRYING' AND next_run_at <= $1
 		ORDER BY next_run_at ASC
@@ -62,35 +113,68 @@ func (s *PostgresStore) ListDueRetries(ctx context.Context, now time.Time, limit
 	}
 	defer rows.Close()
 
-	var ids []string
+	var due []DueRetry
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var job DueRetry
+		if err := rows.Scan(&job.JobID, &job.QueueName); err != nil {
 			return nil, err
 		}
-		ids = append(ids, id)
+		due = append(due, job)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return ids, nil
+	return due, nil
 }
 
-func (s *PostgresStore) MarkRetryEnqueued(ctx context.Context, jobID string) error {
-	_, err := s.pool.Exec(ctx, `
+func (s *PostgresStore) MarkRetryEnqueued(ctx context.Context, jobID string) (err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorScheduler, "retry due"); err != nil {
+		return err
+	}
+	var queueName string
+	err = tx.QueryRow(ctx, `
 		UPDATE jobs
 		SET state = 'PENDING',
 			available_at = NOW(),
 			updated_at = NOW()
-		WHERE job_id = $1
-	`, jobID)
-	return err
+		WHERE job_id = $1 AND state = 'RETRYING'
+		RETURNING queue_name
+	`, jobID).Scan(&queueName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_ = tx.Rollback(ctx)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = storage.InsertOutbox(ctx, tx, jobID, queueName); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	storage.Notify(ctx, s.pool, queueName)
+	return nil
+}
+
+func (s *PostgresStore) MarkJobPublished(ctx context.Context, jobID string) {
+	storage.MarkOutboxPublished(ctx, s.pool, jobID)
 }
 
-func (s *PostgresStore) MarkTerminalFailure(ctx context.Context, jobID string, reason string) error {
+func (s *PostgresStore) MarkTerminalFailure(ctx context.Context, jobID string, reason string) (ok bool, err error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer func() {
 		if err != nil {
@@ -98,17 +182,25 @@ func (s *PostgresStore) MarkTerminalFailure(ctx context.Context, jobID string, r
 		}
 	}()
 
-	_, err = tx.Exec(ctx, `
+	if reason == "" {
+		reason = "terminal failure"
+	}
+	if err = storage.SetEventContext(ctx, tx, storage.ActorScheduler, reason); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET state = 'DLQ',
 			updated_at = NOW()
-		WHERE job_id = $1
+		WHERE job_id = $1 AND state = 'FAILED'
 	`, jobID)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if reason == "" {
-		reason = "terminal failure"
+	// Canceled since it was listed; it must not reach the dead letters.
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return false, nil
 	}
 	_, err = tx.Exec(ctx, `
 		INSERT INTO dead_letters (
@@ -126,11 +218,229 @@ func (s *PostgresStore) MarkTerminalFailure(ctx context.Context, jobID string, r
 			updated_at = NOW()
 	`, jobID, reason)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return false, err
 	}
-	return nil
+	return true, nil
+}
+
+func (s *PostgresStore) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]Schedule, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT schedule_id, cron_expr, timezone, queue_name, job_type, payload, missed_run_policy, priority,
+			max_attempts, initial_delay, backoff, max_delay, jitter, next_fire_at
+		FROM schedules
+		WHERE enabled AND next_fire_at <= $1
+		ORDER BY next_fire_at ASC
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var sched Schedule
+		if err := rows.Scan(
+			&sched.ID,
+			&sched.Cron,
+			&sched.Timezone,
+			&sched.QueueName,
+			&sched.JobType,
+			&sched.Payload,
+			&sched.MissedRunPolicy,
+			&sched.Priority,
+			&sched.MaxAttempts,
+			&sched.InitialDelay,
+			&sched.Backoff,
+			&sched.MaxDelay,
+			&sched.Jitter,
+			&sched.NextFireAt,
+		); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sched)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *PostgresStore) InsertScheduledJob(ctx context.Context, sched Schedule, jobID string, idempotencyKey string, fireAt time.Time) (inserted bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !inserted {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorScheduler, "schedule "+sched.ID); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO jobs (
+			job_id, queue_name, job_type, payload, idempotency_key, state, max_retries,
+			max_attempts, attempt_count, initial_delay, backoff, max_delay, jitter, traceparent,
+			scheduled_at, available_at, next_run_at, priority
+		)
+		VALUES ($1, $2, $3, $4, $5, 'PENDING', $6, $7, 0, $8, $9, $10, $11, '', $12, NOW(), NOW(), $13)
+		ON CONFLICT (queue_name, idempotency_key) DO NOTHING
+	`, jobID, sched.QueueName, sched.JobType, sched.Payload, idempotencyKey, sched.MaxAttempts-1,
+		sched.MaxAttempts, sched.InitialDelay, sched.Backoff, sched.MaxDelay, sched.Jitter, fireAt, sched.Priority)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err = storage.InsertOutbox(ctx, tx, jobID, sched.QueueName); err != nil {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	storage.Notify(ctx, s.pool, sched.QueueName)
+	return true, nil
+}
+
+func (s *PostgresStore) AdvanceSchedule(ctx context.Context, scheduleID string, expected, next time.Time, lastFireAt *time.Time) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE schedules
+		SET next_fire_at = $3,
+			last_fire_at = COALESCE($4, last_fire_at),
+			updated_at = NOW()
+		WHERE schedule_id = $1 AND next_fire_at = $2
+	`, scheduleID, expected, next, lastFireAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) ListReadyJobIDs(ctx context.Context, queueName string, limit int) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT job_id
+		FROM jobs
+		WHERE queue_name = $1
+			AND state = 'PENDING'
+			AND next_run_at <= NOW()
+		ORDER BY next_run_at ASC
+		LIMIT $2
+	`, queueName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *PostgresStore) FilterPendingJobIDs(ctx context.Context, jobIDs []string) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT job_id
+		FROM jobs
+		WHERE job_id IN (SELECT unnest($1::text[])::uuid)
+			AND state = 'PENDING'
+	`, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *PostgresStore) ClaimOutbox(ctx context.Context, now time.Time, createdBefore time.Time, claimFor time.Duration, limit int) ([]OutboxEntry, error) {
+	rows, err := s.pool.Query(ctx, `
+		UPDATE job_outbox
+		SET next_attempt_at = $1::timestamptz + $3::interval
+		WHERE outbox_id IN (
+			SELECT outbox_id
+			FROM job_outbox
+			WHERE published_at IS NULL
+				AND next_attempt_at <= $1
+				AND created_at <= $2
+			ORDER BY outbox_id ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING outbox_id, job_id, queue_name, attempts
+	`, now, createdBefore, claimFor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []OutboxEntry
+	for rows.Next() {
+		var entry OutboxEntry
+		if err := rows.Scan(&entry.ID, &entry.JobID, &entry.QueueName, &entry.Attempts); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *PostgresStore) MarkOutboxDone(ctx context.Context, id int64) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE job_outbox
+		SET published_at = NOW()
+		WHERE outbox_id = $1
+	`, id)
+	return err
+}
+
+func (s *PostgresStore) MarkOutboxFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE job_outbox
+		SET attempts = attempts + 1,
+			next_attempt_at = $2,
+			last_error = $3
+		WHERE outbox_id = $1 AND published_at IS NULL
+	`, id, nextAttemptAt, lastError)
+	return err
+}
+
+func (s *PostgresStore) PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM job_outbox
+		WHERE published_at IS NOT NULL AND published_at < $1
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Actors recorded in job_events.
+const (
+	ActorAPI       = "api"
+	ActorScheduler = "scheduler"
+	ActorReaper    = "reaper"
+)
+
+// WorkerActor is the job_events actor for a worker holding leaseID.
+func WorkerActor(leaseID string) string {
+	return "worker:" + leaseID
+}
+
+// SetEventContext sets the actor and reason the jobs_record_event trigger
+// records for state changes made later in tx. The settings end with tx.
+func SetEventContext(ctx context.Context, tx pgx.Tx, actor string, reason string) error {
+	_, err := tx.Exec(ctx, `SELECT set_config('taskforge.actor', $1, true), set_config('taskforge.reason', $2, true)`, actor, reason)
+	return err
+}
+
+// JobEventsChannel is the LISTEN/NOTIFY channel the jobs_record_event trigger
+// announces every job_events row on. Notifications are sent on commit.
+const JobEventsChannel = "taskforge:job_events"
+
+// JobEventNotice is the payload of a JobEventsChannel notification. FromState
+// is empty for the event recording a job's creation.
+type JobEventNotice struct {
+	ID        int64     `json:"id"`
+	JobID     string    `json:"jobId"`
+	Queue     string    `json:"queue"`
+	JobType   string    `json:"jobType"`
+	FromState string    `json:"fromState"`
+	State     string    `json:"state"`
+	Actor     string    `json:"actor"`
+	At        time.Time `json:"at"`
+}
+
+// JobEventSink receives notices from a JobEventFeed. Resync is called after
+// every (re)connect because notices sent while disconnected are lost.
+type JobEventSink interface {
+	Publish(n JobEventNotice)
+	Resync()
+}
+
+// JobEventFeed holds one dedicated connection that LISTENs on
+// JobEventsChannel and hands every notice to a sink, so a process can follow
+// job state changes without querying per interested caller.
+type JobEventFeed struct {
+	connConfig *pgx.ConnConfig
+}
+
+func NewJobEventFeed(pool *pgxpool.Pool) *JobEventFeed {
+	return &JobEventFeed{connConfig: pool.Config().ConnConfig.Copy()}
+}
+
+// Run listens until ctx is canceled.
+func (f *JobEventFeed) Run(ctx context.Context, sink JobEventSink) {
+	listenLoop(ctx, f.connConfig, "job event feed", []string{JobEventsChannel}, sink.Resync, func(n *pgconn.Notification) {
+		var notice JobEventNotice
+		if err := json.Unmarshal([]byte(n.Payload), &notice); err != nil {
+			slog.Warn("invalid job event notice", "payload", n.Payload, "err", err)
+			return
+		}
+		sink.Publish(notice)
+	})
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotifyChannel is the LISTEN/NOTIFY channel announcing new PENDING jobs in
+// queueName.
+func NotifyChannel(queueName string) string {
+	return "taskforge:" + queueName
+}
+
+// Notify wakes workers listening on queueName. It is best effort: a lost
+// notification only delays pickup until the worker's fallback poll, so errors
+// are not returned.
+func Notify(ctx context.Context, pool *pgxpool.Pool, queueName string) {
+	if _, err := pool.Exec(ctx, `SELECT pg_notify($1, '')`, NotifyChannel(queueName)); err != nil {
+		slog.Warn("notify queue failed", "queue", queueName, "err", err)
+	}
+}
+
+const (
+	listenMinBackoff = 500 * time.Millisecond
+	listenMaxBackoff = 30 * time.Second
+)
+
+// Listener holds a dedicated connection that LISTENs on the channels of a set
+// of queues and turns notifications into wakeups for idle worker slots. It
+// reconnects with backoff when the connection drops.
+type Listener struct {
+	connConfig *pgx.ConnConfig
+	queueNames []string
+	wake       chan struct{}
+}
+
+func NewListener(pool *pgxpool.Pool, queueNames []string) *Listener {
+	return &Listener{
+		connConfig: pool.Config().ConnConfig.Copy(),
+		queueNames: queueNames,
+		wake:       make(chan struct{}, 64),
+	}
+}
+
+// Run listens until ctx is canceled.
+func (l *Listener) Run(ctx context.Context) {
+	channels := make([]string, len(l.queueNames))
+	for i, q := range l.queueNames {
+		channels[i] = NotifyChannel(q)
+	}
+	// Notifications sent while disconnected are lost; wake a slot on every
+	// connect so it checks for work that arrived in the meantime.
+	listenLoop(ctx, l.connConfig, "queue listener", channels, l.signal, func(*pgconn.Notification) {
+		l.signal()
+	})
+}
+
+// listenLoop LISTENs on channels over a dedicated connection until ctx is
+// canceled, reconnecting with backoff. onConnect runs after every (re)connect
+// once the channels are subscribed.
+func listenLoop(ctx context.Context, connConfig *pgx.ConnConfig, name string, channels []string, onConnect func(), onNotify func(*pgconn.Notification)) {
+	backoff := listenMinBackoff
+	for ctx.Err() == nil {
+		start := time.Now()
+		err := listen(ctx, connConfig, channels, onConnect, onNotify)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > listenMaxBackoff {
+			backoff = listenMinBackoff
+		}
+		slog.Warn(name+" disconnected, reconnecting", "err", err, "backoff", backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+func listen(ctx context.Context, connConfig *pgx.ConnConfig, channels []string, onConnect func(), onNotify func(*pgconn.Notification)) error {
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	for _, ch := range channels {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{ch}.Sanitize()); err != nil {
+			return err
+		}
+	}
+	onConnect()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onNotify(n)
+	}
+}
+
+func (l *Listener) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Wait blocks until a notification arrives for one of the listener's queues
+// or timeout elapses. Each notification wakes one waiter. queueNames is
+// ignored; the listener's queues are fixed when it is created.
+func (l *Listener) Wait(ctx context.Context, queueNames []string, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-l.wake:
+		return true, nil
+	case <-timer.C:
+		return false, nil
+	case <-ctx.Done():
+		return false, nil
+	}
+}
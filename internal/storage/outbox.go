@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// InsertOutbox records that jobID must be published to queueName. Call it in
+// the transaction that makes the job PENDING so the publish cannot be lost.
+func InsertOutbox(ctx context.Context, tx pgx.Tx, jobID string, queueName string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO job_outbox (job_id, queue_name)
+		VALUES ($1, $2)
+	`, jobID, queueName)
+	return err
+}
+
+// MarkOutboxPublished marks jobID's pending outbox entries as published after
+// the caller published it directly. It is best effort: an entry left behind is
+// published again by the relay, and duplicate publishes are harmless.
+func MarkOutboxPublished(ctx context.Context, pool *pgxpool.Pool, jobID string) {
+	_, err := pool.Exec(ctx, `
+		UPDATE job_outbox
+		SET published_at = NOW()
+		WHERE job_id = $1 AND published_at IS NULL
+	`, jobID)
+	if err != nil {
+		slog.Warn("mark outbox published failed", "job_id", jobID, "err", err)
+	}
+}
@@ -2,30 +2,94 @@ package worker
 
 import (
 	"context"
+	"log/slog"
 	"time"
+
+	"github.com/pranavko12/taskforge/internal/retry"
 )
 
+// LeaseStore leases jobs to workers. Every lease bumps the job's token, and
+// renewals and outcomes only apply while the caller's Lease is the current
+// one, so a worker whose lease expired cannot overwrite a newer attempt.
 type LeaseStore interface {
-	LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (string, bool, error)
-	AcquireLease(ctx context.Context, jobID string, owner string, now time.Time, leaseFor time.Duration) (bool, error)
-	RenewLease(ctx context.Context, jobID string, leaseID string, extendBy time.Duration) (bool, error)
-	MarkJobSucceeded(ctx context.Context, jobID string, leaseID string) (bool, error)
-	MarkJobFailed(ctx context.Context, jobID string, leaseID string, lastError string) (bool, error)
-	MarkJobTerminal(ctx context.Context, jobID string, leaseID string, lastError string) (bool, error)
-	ListExpiredLeases(ctx context.Context, now time.Time, limit int) ([]string, error)
-	ResetLease(ctx context.Context, jobID string) error
+	// LeaseNextJob returns the job with its new lease token in LeaseToken.
+	LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (Job, bool, error)
+	AcquireLease(ctx context.Context, jobID string, owner string, now time.Time, leaseFor time.Duration) (Lease, bool, error)
+	RenewLease(ctx context.Context, jobID string, lease Lease, extendBy time.Duration) (LeaseStatus, error)
+	// MarkJobSucceeded completes the job and stores result, which may be nil.
+	MarkJobSucceeded(ctx context.Context, jobID string, lease Lease, result *Result) (bool, error)
+	// MarkJobFailed records a retryable failure of the given class.
+	// MarkJobFailed and MarkJobTerminal cancel the job instead if
+	// cancellation was requested, so a canceled job is neither retried nor
+	// dead-lettered.
+	MarkJobFailed(ctx context.Context, jobID string, lease Lease, lastError string, class retry.FailureClass) (bool, error)
+	MarkJobTerminal(ctx context.Context, jobID string, lease Lease, lastError string) (bool, error)
+	// MarkJobCanceled moves a job whose cancellation was requested to
+	// CANCELED. It reports false if no cancellation was requested or the
+	// lease was lost.
+	MarkJobCanceled(ctx context.Context, jobID string, lease Lease) (bool, error)
+	ListExpiredLeases(ctx context.Context, now time.Time, limit int) ([]ExpiredLease, error)
+	// ResetLease returns a job whose lease expired to PENDING and counts the
+	// expiration. A job whose cancellation was requested is canceled instead,
+	// and one whose lease has expired maxExpirations times (if positive) or
+	// max_attempts times is moved to DLQ.
+	ResetLease(ctx context.Context, jobID string, maxExpirations int) (ResetOutcome, error)
 	GetTraceparent(ctx context.Context, jobID string) (string, error)
 }
 
+// Lease identifies one lease of a job: the worker holding it and the fencing
+// token the lease was granted with.
+type Lease struct {
+	Owner string
+	Token int64
+}
+
+// LeaseStatus is what a lease renewal learned about the job.
+type LeaseStatus struct {
+	// Held is false when the lease was lost.
+	Held bool
+	// CancelRequested is set once POST /jobs/{id}/cancel asked the running
+	// job to stop.
+	CancelRequested bool
+}
+
+// ResetOutcome is what ResetLease did with a job whose lease expired.
+type ResetOutcome int
+
+const (
+	// ResetSkipped means the job was no longer IN_PROGRESS.
+	ResetSkipped ResetOutcome = iota
+	// ResetRequeued means the job is PENDING again.
+	ResetRequeued
+	// ResetCanceled means cancellation was requested, so the job is CANCELED.
+	ResetCanceled
+	// ResetPoisoned means the job was dead-lettered as a poison job.
+	ResetPoisoned
+)
+
+// ExpiredLease is an IN_PROGRESS job whose lease ran out, with the queue it
+// belongs to.
+type ExpiredLease struct {
+	JobID     string
+	QueueName string
+	JobType   string
+}
+
+// Queue publishes job IDs to Redis. It may be nil when the deployment runs
+// in Postgres-only mode.
 type Queue interface {
 	Enqueue(ctx context.Context, queueName string, jobID string) error
 }
 
+// LeaseReaper requeues jobs whose worker stopped renewing their lease. A job
+// that keeps expiring its lease is most likely crashing its worker, so after
+// maxExpirations of them it is dead-lettered as a poison job.
 type LeaseReaper struct {
-	store     LeaseStore
-	queue     Queue
-	queueName string
-	limit     int
+	store          LeaseStore
+	queue          Queue
+	queueName      string
+	limit          int
+	maxExpirations int
 }
 
 func NewLeaseReaper(store LeaseStore, queue Queue, queueName string) *LeaseReaper {
@@ -37,18 +101,40 @@ func NewLeaseReaper(store LeaseStore, queue Queue, queueName string) *LeaseReape
 	}
 }
 
+// SetMaxExpirations sets how many lease expirations dead-letter a job. Zero
+// leaves only the job's max_attempts as the bound.
+func (r *LeaseReaper) SetMaxExpirations(n int) {
+	r.maxExpirations = n
+}
+
 func (r *LeaseReaper) RequeueExpiredLeases(ctx context.Context, now time.Time) (int, error) {
-	ids, err := r.store.ListExpiredLeases(ctx, now, r.limit)
+	expired, err := r.store.ListExpiredLeases(ctx, now, r.limit)
 	if err != nil {
 		return 0, err
 	}
-	for _, id := range ids {
-		if err := r.store.ResetLease(ctx, id); err != nil {
+	for _, lease := range expired {
+		outcome, err := r.store.ResetLease(ctx, lease.JobID, r.maxExpirations)
+		if err != nil {
 			return 0, err
 		}
-		if err := r.queue.Enqueue(ctx, r.queueName, id); err != nil {
+		if outcome == ResetPoisoned {
+			slog.Warn("job dead-lettered after repeated lease expirations", "job_id", lease.JobID, "queue", lease.QueueName, "job_type", lease.JobType)
+			incPoisonJobs(lease.QueueName, lease.JobType)
+		}
+		// Only jobs back in PENDING are published.
+		if outcome != ResetRequeued {
+			continue
+		}
+		queueName := lease.QueueName
+		if queueName == "" {
+			queueName = r.queueName
+		}
+		if r.queue == nil {
+			continue
+		}
+		if err := r.queue.Enqueue(ctx, queueName, lease.JobID); err != nil {
 			return 0, err
 		}
 	}
-	return len(ids), nil
+	return len(expired), nil
 }
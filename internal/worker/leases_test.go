@@ -6,6 +6,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/pranavko12/taskforge/internal/retry"
 )
 
 func TestAcquireLeaseExclusive(t *testing.T) {
@@ -16,11 +18,11 @@ func TestAcquireLeaseExclusive(t *testing.T) {
 	w1 := New(store, "worker-1", 10*time.Second)
 	w2 := New(store, "worker-2", 10*time.Second)
 
-	ok, err := w1.Acquire(context.Background(), jobID, now)
+	_, ok, err := w1.Acquire(context.Background(), jobID, now)
 	if err != nil || !ok {
 		t.Fatalf("worker-1 acquire failed: %v ok=%v", err, ok)
 	}
-	ok, err = w2.Acquire(context.Background(), jobID, now)
+	_, ok, err = w2.Acquire(context.Background(), jobID, now)
 	if err != nil {
 		t.Fatalf("worker-2 acquire error: %v", err)
 	}
@@ -37,21 +39,22 @@ func TestRenewLeaseKeepsOwnership(t *testing.T) {
 	w1 := New(store, "worker-1", 5*time.Second)
 	w2 := New(store, "worker-2", 5*time.Second)
 
-	ok, err := w1.Acquire(context.Background(), jobID, now)
+	lease, ok, err := w1.Acquire(context.Background(), jobID, now)
 	if err != nil || !ok {
 		t.Fatalf("worker-1 acquire failed: %v ok=%v", err, ok)
 	}
+	job := Job{ID: jobID, LeaseToken: lease.Token}
 
-	ok, err = w1.Renew(context.Background(), jobID)
-	if err != nil || !ok {
-		t.Fatalf("worker-1 renew failed: %v ok=%v", err, ok)
+	status, err := w1.Renew(context.Background(), job)
+	if err != nil || !status.Held {
+		t.Fatalf("worker-1 renew failed: %v held=%v", err, status.Held)
 	}
 
-	ok, err = w2.Renew(context.Background(), jobID)
+	status, err = w2.Renew(context.Background(), job)
 	if err != nil {
 		t.Fatalf("worker-2 renew error: %v", err)
 	}
-	if ok {
+	if status.Held {
 		t.Fatalf("worker-2 should not renew someone else's lease")
 	}
 }
@@ -61,16 +64,16 @@ func TestRenewLeaseFailsWithWrongLeaseID(t *testing.T) {
 	jobID := "job-1"
 	now := time.Now().UTC()
 
-	ok, err := store.AcquireLease(context.Background(), jobID, "lease-1", now, 5*time.Second)
+	lease, ok, err := store.AcquireLease(context.Background(), jobID, "lease-1", now, 5*time.Second)
 	if err != nil || !ok {
 		t.Fatalf("initial acquire failed: %v ok=%v", err, ok)
 	}
 
-	ok, err = store.RenewLease(context.Background(), jobID, "wrong-lease-id", 5*time.Second)
+	status, err := store.RenewLease(context.Background(), jobID, Lease{Owner: "wrong-lease-id", Token: lease.Token}, 5*time.Second)
 	if err != nil {
 		t.Fatalf("renew returned error: %v", err)
 	}
-	if ok {
+	if status.Held {
 		t.Fatal("expected renew to fail with wrong lease_id")
 	}
 }
@@ -84,7 +87,7 @@ func TestRequeueExpiredLease(t *testing.T) {
 	now := time.Now().UTC()
 
 	w1 := New(store, "worker-1", 1*time.Second)
-	ok, err := w1.Acquire(context.Background(), jobID, now)
+	_, ok, err := w1.Acquire(context.Background(), jobID, now)
 	if err != nil || !ok {
 		t.Fatalf("worker-1 acquire failed: %v ok=%v", err, ok)
 	}
@@ -103,12 +106,90 @@ func TestRequeueExpiredLease(t *testing.T) {
 
 	// Now another worker should be able to acquire.
 	w2 := New(store, "worker-2", 1*time.Second)
-	ok, err = w2.Acquire(context.Background(), jobID, expiredAt)
+	_, ok, err = w2.Acquire(context.Background(), jobID, expiredAt)
 	if err != nil || !ok {
 		t.Fatalf("worker-2 acquire failed after requeue: %v ok=%v", err, ok)
 	}
 }
 
+func TestReaperDeadLettersRepeatedlyExpiringJob(t *testing.T) {
+	store := newFakeLeaseStore()
+	queue := &fakeQueue{}
+	reaper := NewLeaseReaper(store, queue, "jobs:ready")
+	reaper.SetMaxExpirations(2)
+
+	now := time.Now().UTC()
+	w := New(store, "worker-1", time.Second)
+	for i := 0; i < 2; i++ {
+		if _, ok, err := w.Acquire(context.Background(), "job-1", now); err != nil || !ok {
+			t.Fatalf("acquire %d failed: %v ok=%v", i+1, err, ok)
+		}
+		now = now.Add(2 * time.Second)
+		if _, err := reaper.RequeueExpiredLeases(context.Background(), now); err != nil {
+			t.Fatalf("reaper error: %v", err)
+		}
+	}
+
+	if store.state != "DLQ" {
+		t.Fatalf("expected DLQ after 2 expirations, got %s", store.state)
+	}
+	if len(queue.enqueued) != 1 {
+		t.Fatalf("expected only the first expiration to requeue, got %v", queue.enqueued)
+	}
+}
+
+func TestReaperDoesNotRequeueCanceledJob(t *testing.T) {
+	store := newFakeLeaseStore()
+	store.cancelRequested = true
+	queue := &fakeQueue{}
+	reaper := NewLeaseReaper(store, queue, "jobs:ready")
+
+	now := time.Now().UTC()
+	if _, ok, err := New(store, "worker-1", time.Second).Acquire(context.Background(), "job-1", now); err != nil || !ok {
+		t.Fatalf("acquire failed: %v ok=%v", err, ok)
+	}
+	if _, err := reaper.RequeueExpiredLeases(context.Background(), now.Add(2*time.Second)); err != nil {
+		t.Fatalf("reaper error: %v", err)
+	}
+	if store.state != "CANCELED" || len(queue.enqueued) != 0 {
+		t.Fatalf("expected CANCELED and nothing published, got state=%s enqueued=%v", store.state, queue.enqueued)
+	}
+}
+
+func TestStaleLeaseTokenIsFenced(t *testing.T) {
+	store := newFakeLeaseStore()
+	reaper := NewLeaseReaper(store, nil, "jobs:ready")
+	now := time.Now().UTC()
+
+	// The same owner leases the job twice; only the token tells them apart.
+	w := New(store, "worker-1", time.Second)
+	stale, ok, err := w.Acquire(context.Background(), "job-1", now)
+	if err != nil || !ok {
+		t.Fatalf("first acquire failed: %v ok=%v", err, ok)
+	}
+	expiredAt := now.Add(2 * time.Second)
+	if _, err := reaper.RequeueExpiredLeases(context.Background(), expiredAt); err != nil {
+		t.Fatalf("reaper error: %v", err)
+	}
+	current, ok, err := w.Acquire(context.Background(), "job-1", expiredAt)
+	if err != nil || !ok {
+		t.Fatalf("second acquire failed: %v ok=%v", err, ok)
+	}
+	if current.Token <= stale.Token {
+		t.Fatalf("expected the token to increase, got %d then %d", stale.Token, current.Token)
+	}
+
+	if status, err := store.RenewLease(context.Background(), "job-1", stale, time.Second); err != nil || status.Held {
+		t.Fatalf("expected the stale lease not to renew, got %+v err=%v", status, err)
+	}
+	if ok, err := store.MarkJobSucceeded(context.Background(), "job-1", stale, nil); err != nil || ok {
+		t.Fatalf("expected the stale lease not to complete the job, got ok=%v err=%v", ok, err)
+	}
+	if ok, err := store.MarkJobSucceeded(context.Background(), "job-1", current, nil); err != nil || !ok {
+		t.Fatalf("expected the current lease to complete the job, got ok=%v err=%v", ok, err)
+	}
+}
+
 func TestLeaseNextJobConcurrentNoDoubleLease(t *testing.T) {
 	store := newFakeLeaseStore()
 	now := time.Now().UTC()
@@ -121,13 +202,13 @@ func TestLeaseNextJobConcurrentNoDoubleLease(t *testing.T) {
 		wg.Add(1)
 		go func(idx int) {
 			defer wg.Done()
-			jobID, ok, err := store.LeaseNextJob(context.Background(), "jobs:ready", "worker", now, leaseFor)
+			job, ok, err := store.LeaseNextJob(context.Background(), "jobs:ready", "worker", now, leaseFor)
 			if err != nil {
 				t.Errorf("lease next error: %v", err)
 				return
 			}
 			if ok {
-				results <- jobID
+				results <- job.ID
 			}
 		}(i)
 	}
@@ -156,131 +237,180 @@ func (q *fakeQueue) Enqueue(ctx context.Context, queueName string, jobID string)
 }
 
 type fakeLeaseStore struct {
-	mu             sync.Mutex
-	jobID          string
-	queueName      string
-	state          string
-	owner          string
-	expiresAt      time.Time
-	renewCount     int64
-	succeededCount int
-	failedCount    int
-	terminalCount  int
+	mu              sync.Mutex
+	jobID           string
+	queueName       string
+	jobType         string
+	payload         []byte
+	state           string
+	owner           string
+	expiresAt       time.Time
+	renewCount      int64
+	succeededCount  int
+	failedCount     int
+	terminalCount   int
+	canceledCount   int
+	cancelRequested bool
+	renewErr        error
+	failureClass    retry.FailureClass
+	lastError       string
+	token           int64
+	expirations     int
+	maxAttempts     int
+	result          *Result
 }
 
 func newFakeLeaseStore() *fakeLeaseStore {
 	return &fakeLeaseStore{
-		jobID:     "job-1",
-		queueName: "jobs:ready",
-		state:     "PENDING",
+		jobID:       "job-1",
+		queueName:   "jobs:ready",
+		jobType:     "demo",
+		payload:     []byte(`{}`),
+		state:       "PENDING",
+		maxAttempts: 5,
 	}
 }
 
-func (s *fakeLeaseStore) LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (string, bool, error) {
+func (s *fakeLeaseStore) LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (Job, bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if queueName != s.queueName {
-		return "", false, nil
+		return Job{}, false, nil
 	}
 	if s.state != "PENDING" {
-		return "", false, nil
+		return Job{}, false, nil
 	}
 	if s.owner != "" && s.expiresAt.After(now) {
-		return "", false, nil
+		return Job{}, false, nil
 	}
 
 	s.owner = owner
+	s.token++
 	s.expiresAt = now.Add(leaseFor)
 	s.state = "IN_PROGRESS"
-	return s.jobID, true, nil
+	return Job{ID: s.jobID, Queue: s.queueName, Type: s.jobType, Payload: s.payload, Attempt: 1, LeaseToken: s.token}, true, nil
 }
 
-func (s *fakeLeaseStore) AcquireLease(ctx context.Context, jobID string, owner string, now time.Time, leaseFor time.Duration) (bool, error) {
+func (s *fakeLeaseStore) AcquireLease(ctx context.Context, jobID string, owner string, now time.Time, leaseFor time.Duration) (Lease, bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.owner != "" && s.expiresAt.After(now) {
-		return false, nil
+		return Lease{}, false, nil
 	}
 	s.owner = owner
+	s.token++
 	s.expiresAt = now.Add(leaseFor)
 	s.state = "IN_PROGRESS"
-	return true, nil
+	return Lease{Owner: owner, Token: s.token}, true, nil
 }
 
-func (s *fakeLeaseStore) RenewLease(ctx context.Context, jobID string, leaseID string, extendBy time.Duration) (bool, error) {
+func (s *fakeLeaseStore) RenewLease(ctx context.Context, jobID string, lease Lease, extendBy time.Duration) (LeaseStatus, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.owner != leaseID {
-		return false, nil
+	if s.renewErr != nil {
+		return LeaseStatus{}, s.renewErr
+	}
+	if s.owner != lease.Owner || s.token != lease.Token {
+		return LeaseStatus{}, nil
 	}
 	s.expiresAt = time.Now().UTC().Add(extendBy)
 	atomic.AddInt64(&s.renewCount, 1)
-	return true, nil
+	return LeaseStatus{Held: true, CancelRequested: s.cancelRequested}, nil
 }
 
-func (s *fakeLeaseStore) MarkJobSucceeded(ctx context.Context, jobID string, leaseID string) (bool, error) {
+func (s *fakeLeaseStore) MarkJobSucceeded(ctx context.Context, jobID string, lease Lease, result *Result) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.jobID != jobID || s.owner != leaseID || s.state != "IN_PROGRESS" {
+	if s.jobID != jobID || s.owner != lease.Owner || s.token != lease.Token || s.state != "IN_PROGRESS" {
 		return false, nil
 	}
 	s.state = "COMPLETED"
 	s.owner = ""
 	s.expiresAt = time.Time{}
 	s.succeededCount++
+	s.result = result
 	return true, nil
 }
 
-func (s *fakeLeaseStore) MarkJobFailed(ctx context.Context, jobID string, leaseID string, lastError string) (bool, error) {
+func (s *fakeLeaseStore) MarkJobFailed(ctx context.Context, jobID string, lease Lease, lastError string, class retry.FailureClass) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.jobID != jobID || s.owner != leaseID || s.state != "IN_PROGRESS" {
+	if s.jobID != jobID || s.owner != lease.Owner || s.token != lease.Token || s.state != "IN_PROGRESS" {
 		return false, nil
 	}
 	s.state = "FAILED"
 	s.owner = ""
 	s.expiresAt = time.Time{}
 	s.failedCount++
+	s.failureClass = class
+	s.lastError = lastError
 	return true, nil
 }
 
-func (s *fakeLeaseStore) MarkJobTerminal(ctx context.Context, jobID string, leaseID string, lastError string) (bool, error) {
+func (s *fakeLeaseStore) MarkJobTerminal(ctx context.Context, jobID string, lease Lease, lastError string) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.jobID != jobID || s.owner != leaseID || s.state != "IN_PROGRESS" {
+	if s.jobID != jobID || s.owner != lease.Owner || s.token != lease.Token || s.state != "IN_PROGRESS" {
 		return false, nil
 	}
 	s.state = "DLQ"
 	s.owner = ""
 	s.expiresAt = time.Time{}
 	s.terminalCount++
+	s.lastError = lastError
 	return true, nil
 }
 
-func (s *fakeLeaseStore) ListExpiredLeases(ctx context.Context, now time.Time, limit int) ([]string, error) {
+func (s *fakeLeaseStore) MarkJobCanceled(ctx context.Context, jobID string, lease Lease) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jobID != jobID || s.owner != lease.Owner || s.token != lease.Token || s.state != "IN_PROGRESS" || !s.cancelRequested {
+		return false, nil
+	}
+	s.state = "CANCELED"
+	s.owner = ""
+	s.expiresAt = time.Time{}
+	s.canceledCount++
+	return true, nil
+}
+
+func (s *fakeLeaseStore) ListExpiredLeases(ctx context.Context, now time.Time, limit int) ([]ExpiredLease, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.owner != "" && !s.expiresAt.After(now) {
-		return []string{s.jobID}, nil
+		return []ExpiredLease{{JobID: s.jobID, QueueName: s.queueName, JobType: s.jobType}}, nil
 	}
 	return nil, nil
 }
 
-func (s *fakeLeaseStore) ResetLease(ctx context.Context, jobID string) error {
+func (s *fakeLeaseStore) ResetLease(ctx context.Context, jobID string, maxExpirations int) (ResetOutcome, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.state != "IN_PROGRESS" {
+		return ResetSkipped, nil
+	}
 	s.owner = ""
 	s.expiresAt = time.Time{}
+	s.expirations++
+	switch {
+	case s.cancelRequested:
+		s.state = "CANCELED"
+		return ResetCanceled, nil
+	case (maxExpirations > 0 && s.expirations >= maxExpirations) || s.expirations >= s.maxAttempts:
+		s.state = "DLQ"
+		return ResetPoisoned, nil
+	}
 	s.state = "PENDING"
-	return nil
+	return ResetRequeued, nil
 }
 
 func (s *fakeLeaseStore) GetTraceparent(ctx context.Context, jobID string) (string, error) {
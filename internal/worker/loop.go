@@ -2,79 +2,305 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/pranavko12/taskforge/internal/retry"
 )
 
-type ExecuteFunc func(ctx context.Context, jobID string) error
+type ExecuteFunc func(ctx context.Context, job Job) error
+
+// Waiter blocks an idle slot until new work may be available on one of
+// queueNames or timeout elapses. A wakeup is only a hint; the slot still
+// leases from the store.
+type Waiter interface {
+	Wait(ctx context.Context, queueNames []string, timeout time.Duration) (bool, error)
+}
 
 type Loop struct {
 	worker       *Worker
 	store        LeaseStore
-	queueName    string
+	queues       *queueSelector
 	pollInterval time.Duration
+	waiter       Waiter
+	waitTimeout  time.Duration
+	concurrency  int
+	drainTimeout time.Duration
+	// abandonGrace is how long Run still waits once the drain deadline has
+	// canceled in-flight jobs before it gives up on their handlers.
+	abandonGrace time.Duration
+	jobTimeout   time.Duration
+	typeTimeouts map[string]time.Duration
+
+	mu      sync.Mutex
+	running map[string]struct{}
 }
 
 func NewLoop(store LeaseStore, queueName string, leaseID string, leaseFor time.Duration) *Loop {
 	return &Loop{
 		worker:       New(store, leaseID, leaseFor),
 		store:        store,
-		queueName:    queueName,
+		queues:       newQueueSelector(QueueModeWeighted, []LoopQueue{{Name: queueName, Weight: 1}}),
 		pollInterval: 100 * time.Millisecond,
+		concurrency:  1,
+		abandonGrace: 5 * time.Second,
+		running:      map[string]struct{}{},
+	}
+}
+
+// SetConcurrency sets how many jobs Run leases and executes in parallel.
+func (l *Loop) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	l.concurrency = n
+}
+
+// SetQueues replaces the queue given to NewLoop with several queues, chosen
+// per lease attempt according to mode.
+func (l *Loop) SetQueues(mode QueueMode, queues []LoopQueue) {
+	if len(queues) == 0 {
+		return
 	}
+	l.queues = newQueueSelector(mode, queues)
 }
 
+// SetWaiter makes idle slots block on w instead of polling the store every
+// poll interval. Slots still try to lease at least once per timeout, which
+// picks up delayed jobs and any wakeups w missed.
+func (l *Loop) SetWaiter(w Waiter, timeout time.Duration) {
+	l.waiter = w
+	l.waitTimeout = timeout
+}
+
+// SetDrainTimeout bounds how long Run waits for in-flight jobs after its
+// context is canceled. Jobs still running at the deadline have their context
+// canceled; handlers that ignore it are abandoned after a short grace period
+// and their jobs left to lease expiry. Zero waits indefinitely.
+func (l *Loop) SetDrainTimeout(d time.Duration) {
+	l.drainTimeout = d
+}
+
+// SetJobTimeouts sets the execution timeout for jobs submitted without one:
+// byType by job type, else def. Zero means no timeout.
+func (l *Loop) SetJobTimeouts(def time.Duration, byType map[string]time.Duration) {
+	l.jobTimeout = def
+	l.typeTimeouts = byType
+}
+
+// timeoutFor is the execution timeout that applies to job.
+func (l *Loop) timeoutFor(job Job) time.Duration {
+	if job.Timeout > 0 {
+		return job.Timeout
+	}
+	if d, ok := l.typeTimeouts[job.Type]; ok {
+		return d
+	}
+	return l.jobTimeout
+}
+
+// Run starts one lease-and-execute slot per unit of concurrency and blocks until
+// ctx is canceled and in-flight jobs have drained, or a slot fails.
 func (l *Loop) Run(ctx context.Context, execute ExecuteFunc) error {
+	// Graceful shutdown: leased jobs run on a context detached from ctx so they
+	// can finish; it is only canceled once the drain deadline passes.
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+	leaseCtx, stopLeasing := context.WithCancel(ctx)
+	defer stopLeasing()
+
+	errs := make(chan error, l.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < l.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.runSlot(leaseCtx, jobCtx, execute); err != nil {
+				errs <- err
+				stopLeasing()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-leaseCtx.Done():
+		var deadline <-chan time.Time
+		if l.drainTimeout > 0 {
+			timer := time.NewTimer(l.drainTimeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		select {
+		case <-done:
+		case <-deadline:
+			cancelJobs()
+			grace := time.NewTimer(l.abandonGrace)
+			defer grace.Stop()
+			select {
+			case <-done:
+			case <-grace.C:
+				// Their leases expire once this process exits and the
+				// reaper requeues them.
+				slog.Warn("abandoning jobs whose handlers ignored cancellation", "job_ids", l.runningJobIDs())
+			}
+		}
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (l *Loop) runSlot(leaseCtx context.Context, jobCtx context.Context, execute ExecuteFunc) error {
 	for {
 		select {
-		case <-ctx.Done():
+		case <-leaseCtx.Done():
 			return nil
 		default:
 		}
 
-		jobID, ok, err := l.worker.LeaseNext(ctx, l.queueName, time.Now().UTC())
+		leased, err := l.leaseAndProcess(leaseCtx, jobCtx, execute)
 		if err != nil {
 			return err
 		}
-		if !ok {
-			timer := time.NewTimer(l.pollInterval)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				return nil
-			case <-timer.C:
+		if !leased {
+			l.idle(leaseCtx)
+		}
+	}
+}
+
+// idle waits for a wakeup, or one poll interval when there is no waiter or
+// the waiter fails.
+func (l *Loop) idle(ctx context.Context) {
+	if l.waiter != nil {
+		_, err := l.waiter.Wait(ctx, l.queues.names(), l.waitTimeout)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		slog.Warn("worker wakeup wait failed, falling back to polling", "err", err)
+	}
+	timer := time.NewTimer(l.pollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// leaseAndProcess tries each queue in selection order, skipping queues at
+// their concurrency cap, and processes the first job leased. It reports
+// whether a job was leased.
+func (l *Loop) leaseAndProcess(leaseCtx context.Context, jobCtx context.Context, execute ExecuteFunc) (bool, error) {
+	for _, q := range l.queues.order() {
+		if q.Throttler != nil && !q.Throttler.TryAcquire() {
+			continue
+		}
+		job, ok, err := l.worker.LeaseNext(leaseCtx, q.Name, time.Now().UTC())
+		if q.Throttler != nil && err == nil {
+			q.Throttler.settle(ok)
+		}
+		if err != nil || !ok {
+			if q.Throttler != nil {
+				q.Throttler.Release()
+			}
+			if err != nil {
+				if leaseCtx.Err() != nil {
+					return false, nil
+				}
+				return false, err
 			}
 			continue
 		}
+		incJobsLeased(job.Queue, job.Priority)
 
-		// Graceful shutdown: once leased, finish the current job even if run context is canceled.
-		runCtx := context.WithoutCancel(ctx)
-		if err := l.ProcessOne(runCtx, jobID, execute); err != nil {
-			return err
+		l.mu.Lock()
+		l.running[job.ID] = struct{}{}
+		l.mu.Unlock()
+		err = l.ProcessOne(jobCtx, job, execute)
+		l.mu.Lock()
+		delete(l.running, job.ID)
+		l.mu.Unlock()
+		if q.Throttler != nil {
+			q.Throttler.Release()
 		}
+		return true, err
+	}
+	return false, nil
+}
+
+// runningJobIDs returns the IDs of the jobs Run is executing, sorted.
+func (l *Loop) runningJobIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ids := make([]string, 0, len(l.running))
+	for id := range l.running {
+		ids = append(ids, id)
 	}
+	slices.Sort(ids)
+	return ids
 }
 
-func (l *Loop) ProcessOne(ctx context.Context, jobID string, execute ExecuteFunc) error {
+func (l *Loop) ProcessOne(ctx context.Context, job Job, execute ExecuteFunc) error {
+	jobID := job.ID
+	lease := l.worker.lease(job)
 	hbCtx, stopHeartbeat := context.WithCancel(context.Background())
 	hbDone := make(chan error, 1)
+	ctx, cancelJob := context.WithCancelCause(ctx)
+	defer cancelJob(nil)
 	go func() {
-		hbDone <- l.worker.Heartbeat(hbCtx, jobID)
+		hbDone <- l.worker.Heartbeat(hbCtx, job, cancelJob)
 	}()
+	timeout := l.timeoutFor(job)
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, timeout, ErrJobTimeout)
+		defer cancelTimeout()
+	}
 
-	runErr := execute(ctx, jobID)
+	ctx, results := withResultSlot(ctx)
+	runErr := execute(ctx, job)
 
 	stopHeartbeat()
-	hbErr := <-hbDone
-	if hbErr != nil {
-		return hbErr
+	// Without the lease the job belongs to whichever worker leases it next;
+	// reporting an outcome here could only clobber that run.
+	if hbErr := <-hbDone; errors.Is(hbErr, ErrLeaseLost) {
+		return nil
+	}
+
+	// A handler that finished despite the cancel request keeps its result.
+	if runErr != nil && errors.Is(context.Cause(ctx), ErrJobCanceled) {
+		ok, err := l.store.MarkJobCanceled(context.Background(), jobID, lease)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("failed to mark job %s canceled: lease mismatch or invalid state", jobID)
+		}
+		return nil
+	}
+
+	if runErr != nil && errors.Is(context.Cause(ctx), ErrJobTimeout) {
+		incJobTimeouts(job.Queue, job.Type)
+		runErr = retry.Timeout(fmt.Errorf("%w after %s: %v", ErrJobTimeout, timeout, runErr))
 	}
 
 	if runErr != nil {
-		if retry.ClassifyError(runErr) == retry.ClassRetryable {
-			ok, err := l.store.MarkJobFailed(context.Background(), jobID, l.worker.leaseID, runErr.Error())
+		if class := retry.ClassifyError(runErr); class.IsRetryable() {
+			ok, err := l.store.MarkJobFailed(context.Background(), jobID, lease, runErr.Error(), class)
 			if err != nil {
 				return err
 			}
@@ -84,7 +310,7 @@ func (l *Loop) ProcessOne(ctx context.Context, jobID string, execute ExecuteFunc
 			return nil
 		}
 
-		ok, err := l.store.MarkJobTerminal(context.Background(), jobID, l.worker.leaseID, runErr.Error())
+		ok, err := l.store.MarkJobTerminal(context.Background(), jobID, lease, runErr.Error())
 		if err != nil {
 			return err
 		}
@@ -94,7 +320,7 @@ func (l *Loop) ProcessOne(ctx context.Context, jobID string, execute ExecuteFunc
 		return nil
 	}
 
-	ok, err := l.store.MarkJobSucceeded(context.Background(), jobID, l.worker.leaseID)
+	ok, err := l.store.MarkJobSucceeded(context.Background(), jobID, lease, results.get())
 	if err != nil {
 		return err
 	}
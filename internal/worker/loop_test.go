@@ -3,9 +3,13 @@ package worker
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/pranavko12/taskforge/internal/retry"
 )
 
 func TestLoopGracefulShutdownFinishesCurrentJob(t *testing.T) {
@@ -18,7 +22,7 @@ func TestLoopGracefulShutdownFinishesCurrentJob(t *testing.T) {
 	runCtx, cancel := context.WithCancel(context.Background())
 
 	go func() {
-		_ = loop.Run(runCtx, func(ctx context.Context, jobID string) error {
+		_ = loop.Run(runCtx, func(ctx context.Context, job Job) error {
 			started <- struct{}{}
 			time.Sleep(30 * time.Millisecond)
 			done <- struct{}{}
@@ -39,7 +43,7 @@ func TestLoopGracefulShutdownFinishesCurrentJob(t *testing.T) {
 func TestProcessOneCrashSimulationCancelContextMidJob(t *testing.T) {
 	store := newFakeLeaseStore()
 	now := time.Now().UTC()
-	ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", now, 30*time.Millisecond)
+	lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", now, 30*time.Millisecond)
 	if err != nil || !ok {
 		t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
 	}
@@ -54,7 +58,7 @@ func TestProcessOneCrashSimulationCancelContextMidJob(t *testing.T) {
 		cancel()
 	}()
 
-	err = loop.ProcessOne(execCtx, "job-1", func(ctx context.Context, jobID string) error {
+	err = loop.ProcessOne(execCtx, Job{ID: "job-1", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error {
 		<-ctx.Done()
 		return context.Canceled
 	})
@@ -72,16 +76,140 @@ func TestProcessOneCrashSimulationCancelContextMidJob(t *testing.T) {
 	}
 }
 
+func TestProcessOneCancelRequestCancelsHandler(t *testing.T) {
+	store := newFakeLeaseStore()
+	lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), 20*time.Millisecond)
+	if err != nil || !ok {
+		t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
+	}
+	store.mu.Lock()
+	store.cancelRequested = true
+	store.mu.Unlock()
+	loop := NewLoop(store, "jobs:ready", "lease-1", 20*time.Millisecond)
+
+	var cause error
+	err = loop.ProcessOne(context.Background(), Job{ID: "job-1", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error {
+		select {
+		case <-ctx.Done():
+			cause = context.Cause(ctx)
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("handler was not canceled")
+		}
+	})
+	if err != nil {
+		t.Fatalf("process one returned error: %v", err)
+	}
+	if !errors.Is(cause, ErrJobCanceled) {
+		t.Fatalf("expected ErrJobCanceled cause, got %v", cause)
+	}
+	if store.canceledCount != 1 || store.failedCount != 0 || store.state != "CANCELED" {
+		t.Fatalf("expected the job to be canceled, got state=%s canceled=%d failed=%d", store.state, store.canceledCount, store.failedCount)
+	}
+}
+
+func TestProcessOneLeaseLostCancelsHandler(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(store *fakeLeaseStore)
+	}{
+		{"reclaimed", func(store *fakeLeaseStore) { store.owner = "lease-2" }},
+		{"renew errors", func(store *fakeLeaseStore) { store.renewErr = errors.New("connection refused") }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeLeaseStore()
+			lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), 20*time.Millisecond)
+			if err != nil || !ok {
+				t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
+			}
+			store.mu.Lock()
+			tc.setup(store)
+			store.mu.Unlock()
+			loop := NewLoop(store, "jobs:ready", "lease-1", 20*time.Millisecond)
+
+			var cause error
+			err = loop.ProcessOne(context.Background(), Job{ID: "job-1", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error {
+				select {
+				case <-ctx.Done():
+					cause = context.Cause(ctx)
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return errors.New("handler was not canceled")
+				}
+			})
+			if err != nil {
+				t.Fatalf("process one returned error: %v", err)
+			}
+			if !errors.Is(cause, ErrLeaseLost) {
+				t.Fatalf("expected ErrLeaseLost cause, got %v", cause)
+			}
+			if store.failedCount != 0 || store.terminalCount != 0 || store.state != "IN_PROGRESS" {
+				t.Fatalf("expected no outcome to be reported, got state=%s failed=%d terminal=%d", store.state, store.failedCount, store.terminalCount)
+			}
+		})
+	}
+}
+
+func TestProcessOneTimeoutFailsAsRetryableTimeout(t *testing.T) {
+	store := newFakeLeaseStore()
+	lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), time.Second)
+	if err != nil || !ok {
+		t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
+	}
+	loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+	loop.SetJobTimeouts(time.Hour, map[string]time.Duration{"demo": 20 * time.Millisecond})
+
+	var cause error
+	start := time.Now()
+	err = loop.ProcessOne(context.Background(), Job{ID: "job-1", Type: "demo", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error {
+		<-ctx.Done()
+		cause = context.Cause(ctx)
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("process one returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the job type timeout to apply, took %s", elapsed)
+	}
+	if !errors.Is(cause, ErrJobTimeout) {
+		t.Fatalf("expected ErrJobTimeout cause, got %v", cause)
+	}
+	if store.failedCount != 1 || store.terminalCount != 0 || store.failureClass != retry.ClassTimeout {
+		t.Fatalf("expected a retryable timeout failure, got failed=%d terminal=%d class=%q", store.failedCount, store.terminalCount, store.failureClass)
+	}
+}
+
+func TestLoopTimeoutForPrefersJobThenType(t *testing.T) {
+	loop := NewLoop(newFakeLeaseStore(), "jobs:ready", "lease-1", time.Second)
+	loop.SetJobTimeouts(time.Minute, map[string]time.Duration{"report": time.Hour})
+
+	cases := []struct {
+		job  Job
+		want time.Duration
+	}{
+		{Job{Type: "report", Timeout: time.Second}, time.Second},
+		{Job{Type: "report"}, time.Hour},
+		{Job{Type: "email"}, time.Minute},
+	}
+	for _, c := range cases {
+		if got := loop.timeoutFor(c.job); got != c.want {
+			t.Fatalf("%+v: expected %s, got %s", c.job, c.want, got)
+		}
+	}
+}
+
 func TestProcessOneSucceedFailTransitions(t *testing.T) {
 	store := newFakeLeaseStore()
 	now := time.Now().UTC()
-	ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", now, 50*time.Millisecond)
+	lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", now, 50*time.Millisecond)
 	if err != nil || !ok {
 		t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
 	}
 	loop := NewLoop(store, "jobs:ready", "lease-1", 50*time.Millisecond)
 
-	if err := loop.ProcessOne(context.Background(), "job-1", func(ctx context.Context, jobID string) error { return nil }); err != nil {
+	if err := loop.ProcessOne(context.Background(), Job{ID: "job-1", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error { return nil }); err != nil {
 		t.Fatalf("success path failed: %v", err)
 	}
 	if store.succeededCount != 1 {
@@ -91,14 +219,244 @@ func TestProcessOneSucceedFailTransitions(t *testing.T) {
 	store.state = "PENDING"
 	store.owner = ""
 	store.expiresAt = time.Time{}
-	ok, err = store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), 50*time.Millisecond)
+	lease, ok, err = store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), 50*time.Millisecond)
 	if err != nil || !ok {
 		t.Fatalf("second acquire lease failed: %v ok=%v", err, ok)
 	}
-	if err := loop.ProcessOne(context.Background(), "job-1", func(ctx context.Context, jobID string) error { return errors.New("boom") }); err != nil {
+	if err := loop.ProcessOne(context.Background(), Job{ID: "job-1", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error { return errors.New("boom") }); err != nil {
 		t.Fatalf("failure path failed: %v", err)
 	}
 	if store.terminalCount != 1 {
 		t.Fatalf("expected terminalCount=1 got %d", store.terminalCount)
 	}
 }
+
+func TestLoopRunExecutesJobsConcurrently(t *testing.T) {
+	store := newPoolStore(4)
+	loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+	loop.pollInterval = 5 * time.Millisecond
+	loop.SetConcurrency(4)
+
+	var running int32
+	allStarted := make(chan struct{})
+	var once sync.Once
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- loop.Run(runCtx, func(ctx context.Context, job Job) error {
+			if atomic.AddInt32(&running, 1) == 4 {
+				once.Do(func() { close(allStarted) })
+			}
+			select {
+			case <-allStarted:
+				return nil
+			case <-time.After(time.Second):
+				return errors.New("jobs did not run in parallel")
+			}
+		})
+	}()
+
+	select {
+	case <-allStarted:
+	case <-time.After(time.Second):
+		t.Fatal("expected 4 jobs to be in flight at once")
+	}
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	if got := store.succeeded(); got != 4 {
+		t.Fatalf("expected 4 succeeded, got %d", got)
+	}
+}
+
+func TestLoopRunDrainDeadlineCancelsInFlightJobs(t *testing.T) {
+	store := newPoolStore(1)
+	loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+	loop.pollInterval = 5 * time.Millisecond
+	loop.SetDrainTimeout(30 * time.Millisecond)
+
+	started := make(chan struct{})
+	runCtx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- loop.Run(runCtx, func(ctx context.Context, job Job) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected Run to return after drain deadline")
+	}
+	if got := store.failed(); got != 1 {
+		t.Fatalf("expected canceled job to be marked failed, got %d", got)
+	}
+}
+
+func TestLoopRunAbandonsHandlersThatIgnoreCancellation(t *testing.T) {
+	store := newPoolStore(1)
+	loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+	loop.pollInterval = 5 * time.Millisecond
+	loop.SetDrainTimeout(20 * time.Millisecond)
+	loop.abandonGrace = 20 * time.Millisecond
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	runCtx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- loop.Run(runCtx, func(ctx context.Context, job Job) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected Run to return after the abandon grace period")
+	}
+	if got := loop.runningJobIDs(); len(got) != 1 || got[0] != "job-1" {
+		t.Fatalf("expected job-1 to be left running, got %v", got)
+	}
+	if got := store.succeeded() + store.failed(); got != 0 {
+		t.Fatalf("expected no outcome for the abandoned job, got %d", got)
+	}
+}
+
+// poolStore hands out a fixed set of pending jobs to any number of workers.
+type poolStore struct {
+	*fakeLeaseStore
+	mu             sync.Mutex
+	pending        []string
+	attempts       int
+	succeededCount int
+	failedCount    int
+}
+
+func newPoolStore(n int) *poolStore {
+	s := &poolStore{fakeLeaseStore: newFakeLeaseStore()}
+	for i := 1; i <= n; i++ {
+		s.pending = append(s.pending, fmt.Sprintf("job-%d", i))
+	}
+	return s
+}
+
+func (s *poolStore) LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if len(s.pending) == 0 {
+		return Job{}, false, nil
+	}
+	id := s.pending[0]
+	s.pending = s.pending[1:]
+	return Job{ID: id, Queue: queueName, Type: "demo", Attempt: 1}, true, nil
+}
+
+func (s *poolStore) RenewLease(ctx context.Context, jobID string, lease Lease, extendBy time.Duration) (LeaseStatus, error) {
+	return LeaseStatus{Held: true}, nil
+}
+
+func (s *poolStore) MarkJobSucceeded(ctx context.Context, jobID string, lease Lease, result *Result) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.succeededCount++
+	return true, nil
+}
+
+func (s *poolStore) MarkJobFailed(ctx context.Context, jobID string, lease Lease, lastError string, class retry.FailureClass) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failedCount++
+	return true, nil
+}
+
+func (s *poolStore) leaseAttempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+func (s *poolStore) succeeded() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.succeededCount
+}
+
+func (s *poolStore) failed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failedCount
+}
+
+func TestLoopWaitsForWakeupInsteadOfPolling(t *testing.T) {
+	store := newPoolStore(0)
+	waiter := &chanWaiter{wake: make(chan struct{})}
+	loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+	loop.pollInterval = time.Millisecond
+	loop.SetWaiter(waiter, time.Minute)
+
+	ran := make(chan string, 1)
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = loop.Run(runCtx, func(ctx context.Context, job Job) error {
+			ran <- job.ID
+			return nil
+		})
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if n := store.leaseAttempts(); n != 1 {
+		t.Fatalf("expected a single lease attempt while idle, got %d", n)
+	}
+
+	store.mu.Lock()
+	store.pending = append(store.pending, "job-1")
+	store.mu.Unlock()
+	waiter.wake <- struct{}{}
+
+	select {
+	case id := <-ran:
+		if id != "job-1" {
+			t.Fatalf("expected job-1, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected wakeup to lease the job")
+	}
+}
+
+// chanWaiter wakes one idle slot per value sent on wake.
+type chanWaiter struct {
+	wake chan struct{}
+}
+
+func (w *chanWaiter) Wait(ctx context.Context, queueNames []string, timeout time.Duration) (bool, error) {
+	select {
+	case <-w.wake:
+		return true, nil
+	case <-ctx.Done():
+		return false, nil
+	case <-time.After(timeout):
+		return false, nil
+	}
+}
@@ -9,3 +9,23 @@ func incConcurrencyThrottled(queue string) {
 func incRateThrottled(queue string) {
 	metrics.IncRateThrottled(queue)
 }
+
+func incJobsLeased(queue string, priority int) {
+	metrics.IncJobsLeased(queue, priority)
+}
+
+func incLeaseLost(queue string, reason string) {
+	metrics.IncWorkerLeaseLost(queue, reason)
+}
+
+func incJobTimeouts(queue string, jobType string) {
+	metrics.IncJobTimeouts(queue, jobType)
+}
+
+func incPoisonJobs(queue string, jobType string) {
+	metrics.IncPoisonJobs(queue, jobType)
+}
+
+func incJobPanics(queue string, jobType string) {
+	metrics.IncJobPanics(queue, jobType)
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"sort"
+	"sync"
+)
+
+type QueueMode string
+
+const (
+	// QueueModeWeighted spreads lease attempts across queues in proportion to
+	// their weights using smooth weighted round-robin.
+	QueueModeWeighted QueueMode = "weighted"
+	// QueueModeStrict always tries queues in descending weight order, so a
+	// lower queue is only leased from while every higher one is empty.
+	QueueModeStrict QueueMode = "strict"
+)
+
+// LoopQueue is one queue a Loop leases from.
+type LoopQueue struct {
+	Name   string
+	Weight int
+	// Throttler, if set, caps the queue's in-flight jobs. The loop skips the
+	// queue while the cap is reached.
+	Throttler *Throttler
+}
+
+// queueSelector decides which queue each lease attempt tries first. It is
+// shared by all slots of a Loop.
+type queueSelector struct {
+	mode   QueueMode
+	queues []LoopQueue
+	// ranked is queues ordered by descending weight, ties in configured order.
+	ranked []LoopQueue
+
+	mu      sync.Mutex
+	current []int
+	total   int
+}
+
+func newQueueSelector(mode QueueMode, queues []LoopQueue) *queueSelector {
+	sel := &queueSelector{
+		mode:    mode,
+		queues:  make([]LoopQueue, len(queues)),
+		current: make([]int, len(queues)),
+	}
+	for i, q := range queues {
+		if q.Weight < 1 {
+			q.Weight = 1
+		}
+		sel.queues[i] = q
+		sel.total += q.Weight
+	}
+	sel.ranked = append([]LoopQueue(nil), sel.queues...)
+	sort.SliceStable(sel.ranked, func(i, j int) bool {
+		return sel.ranked[i].Weight > sel.ranked[j].Weight
+	})
+	return sel
+}
+
+func (s *queueSelector) names() []string {
+	names := make([]string, len(s.ranked))
+	for i, q := range s.ranked {
+		names[i] = q.Name
+	}
+	return names
+}
+
+// order returns the queues to try for one lease attempt. In weighted mode the
+// round-robin pick comes first and the rest follow by weight, so a slot does
+// not sit idle while the picked queue is empty or at its cap.
+func (s *queueSelector) order() []LoopQueue {
+	if s.mode == QueueModeStrict || len(s.queues) == 1 {
+		return s.ranked
+	}
+
+	s.mu.Lock()
+	best := 0
+	for i, q := range s.queues {
+		s.current[i] += q.Weight
+		if s.current[i] > s.current[best] {
+			best = i
+		}
+	}
+	s.current[best] -= s.total
+	s.mu.Unlock()
+
+	order := make([]LoopQueue, 0, len(s.queues))
+	order = append(order, s.queues[best])
+	for _, q := range s.ranked {
+		if q.Name != s.queues[best].Name {
+			order = append(order, q)
+		}
+	}
+	return order
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueueSelectorWeightedSharesAttempts(t *testing.T) {
+	sel := newQueueSelector(QueueModeWeighted, []LoopQueue{
+		{Name: "critical", Weight: 5},
+		{Name: "default", Weight: 3},
+		{Name: "bulk", Weight: 1},
+	})
+
+	counts := map[string]int{}
+	for i := 0; i < 90; i++ {
+		order := sel.order()
+		if len(order) != 3 {
+			t.Fatalf("expected every queue in the order, got %v", order)
+		}
+		counts[order[0].Name]++
+	}
+	if counts["critical"] != 50 || counts["default"] != 30 || counts["bulk"] != 10 {
+		t.Fatalf("expected 50/30/10 first picks, got %v", counts)
+	}
+}
+
+func TestQueueSelectorStrictKeepsWeightOrder(t *testing.T) {
+	sel := newQueueSelector(QueueModeStrict, []LoopQueue{
+		{Name: "bulk", Weight: 1},
+		{Name: "critical", Weight: 5},
+	})
+	for i := 0; i < 3; i++ {
+		order := sel.order()
+		if order[0].Name != "critical" || order[1].Name != "bulk" {
+			t.Fatalf("expected critical before bulk, got %v", order)
+		}
+	}
+}
+
+func TestLoopStrictModeDrainsHigherQueueFirst(t *testing.T) {
+	store := newQueuesStore(map[string]int{"critical": 3, "bulk": 3})
+	loop := NewLoop(store, "bulk", "lease-1", time.Second)
+	loop.pollInterval = 5 * time.Millisecond
+	loop.SetQueues(QueueModeStrict, []LoopQueue{{Name: "critical", Weight: 5}, {Name: "bulk", Weight: 1}})
+
+	var mu sync.Mutex
+	var seen []string
+	runCtx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- loop.Run(runCtx, func(ctx context.Context, job Job) error {
+			mu.Lock()
+			seen = append(seen, job.Queue)
+			if len(seen) == 6 {
+				cancel()
+			}
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	case <-time.After(time.Second):
+		cancel()
+		t.Fatal("expected all jobs to run")
+	}
+	want := []string{"critical", "critical", "critical", "bulk", "bulk", "bulk"}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+	}
+}
+
+func TestLoopHonorsPerQueueConcurrencyCap(t *testing.T) {
+	store := newQueuesStore(map[string]int{"bulk": 6})
+	capped := NewThrottler("bulk", 1, 0)
+	defer capped.Close()
+
+	loop := NewLoop(store, "bulk", "lease-1", time.Second)
+	loop.pollInterval = 5 * time.Millisecond
+	loop.SetConcurrency(3)
+	loop.SetQueues(QueueModeWeighted, []LoopQueue{{Name: "bulk", Weight: 1, Throttler: capped}})
+
+	var running, peak, done int32
+	runCtx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- loop.Run(runCtx, func(ctx context.Context, job Job) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			if atomic.AddInt32(&done, 1) == 6 {
+				cancel()
+			}
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		cancel()
+		t.Fatal("expected all jobs to run")
+	}
+	if p := atomic.LoadInt32(&peak); p != 1 {
+		t.Fatalf("expected at most 1 bulk job in flight, got %d", p)
+	}
+}
+
+// queuesStore hands out pending jobs from several named queues.
+type queuesStore struct {
+	*fakeLeaseStore
+	mu      sync.Mutex
+	pending map[string]int
+	next    int
+}
+
+func newQueuesStore(pending map[string]int) *queuesStore {
+	return &queuesStore{fakeLeaseStore: newFakeLeaseStore(), pending: pending}
+}
+
+func (s *queuesStore) LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[queueName] == 0 {
+		return Job{}, false, nil
+	}
+	s.pending[queueName]--
+	s.next++
+	return Job{ID: fmt.Sprintf("%s-%d", queueName, s.next), Queue: queueName, Type: "demo", Attempt: 1}, true, nil
+}
+
+func (s *queuesStore) RenewLease(ctx context.Context, jobID string, lease Lease, extendBy time.Duration) (LeaseStatus, error) {
+	return LeaseStatus{Held: true}, nil
+}
+
+func (s *queuesStore) MarkJobSucceeded(ctx context.Context, jobID string, lease Lease, result *Result) (bool, error) {
+	return true, nil
+}
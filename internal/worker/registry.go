@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pranavko12/taskforge/internal/retry"
+)
+
+var ErrUnknownJobType = errors.New("unknown job type")
+
+// Job is a leased job as seen by handlers.
+type Job struct {
+	ID      string
+	Queue   string
+	Type    string
+	Payload json.RawMessage
+	Attempt int
+	// Priority is 0-9; higher-priority jobs are leased first.
+	Priority int
+	// ReadyAt is when the job became eligible to run (its next_run_at).
+	ReadyAt time.Time
+	// LeaseToken increases with every lease of the job. Handlers can pass
+	// it to downstream stores as a fencing token and reject writes carrying
+	// an older one, so a worker that lost its lease cannot clobber a newer
+	// attempt's side effects.
+	LeaseToken int64
+	// Timeout is the job's own execution timeout; zero means the worker's
+	// default for the job type applies.
+	Timeout time.Duration
+}
+
+type Handler func(ctx context.Context, job Job) error
+
+// Registry maps job types to the handlers that execute them.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+func (r *Registry) Register(jobType string, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[jobType] = h
+}
+
+func (r *Registry) Lookup(jobType string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[jobType]
+	return h, ok
+}
+
+// Dispatch runs the handler registered for job.Type. Jobs with no handler fail
+// terminally so they land in the DLQ instead of being retried forever.
+func (r *Registry) Dispatch(ctx context.Context, job Job) error {
+	h, ok := r.Lookup(job.Type)
+	if !ok {
+		return retry.Terminal(fmt.Errorf("%w %q", ErrUnknownJobType, job.Type))
+	}
+	return h(ctx, job)
+}
+
+// HandleJSON adapts a typed handler by decoding the job payload into T.
+// Payloads that do not decode are terminal failures.
+func HandleJSON[T any](fn func(ctx context.Context, job Job, payload T) error) Handler {
+	return func(ctx context.Context, job Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return retry.Terminal(fmt.Errorf("decode %s payload: %w", job.Type, err))
+		}
+		return fn(ctx, job, payload)
+	}
+}
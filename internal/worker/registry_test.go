@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pranavko12/taskforge/internal/retry"
+)
+
+func TestRegistryDispatchesByJobType(t *testing.T) {
+	reg := NewRegistry()
+	var got string
+	reg.Register("email.send", func(ctx context.Context, job Job) error {
+		got = job.ID
+		return nil
+	})
+
+	if err := reg.Dispatch(context.Background(), Job{ID: "job-1", Type: "email.send"}); err != nil {
+		t.Fatalf("dispatch error: %v", err)
+	}
+	if got != "job-1" {
+		t.Fatalf("expected handler to receive job-1, got %q", got)
+	}
+}
+
+func TestRegistryUnknownJobTypeIsTerminal(t *testing.T) {
+	reg := NewRegistry()
+
+	err := reg.Dispatch(context.Background(), Job{ID: "job-1", Type: "missing"})
+	if !errors.Is(err, ErrUnknownJobType) {
+		t.Fatalf("expected ErrUnknownJobType, got %v", err)
+	}
+	if got := retry.ClassifyError(err); got != retry.ClassTerminal {
+		t.Fatalf("expected terminal, got %s", got)
+	}
+}
+
+func TestHandleJSONDecodesPayload(t *testing.T) {
+	type emailPayload struct {
+		To string `json:"to"`
+	}
+	reg := NewRegistry()
+	var to string
+	reg.Register("email.send", HandleJSON(func(ctx context.Context, job Job, p emailPayload) error {
+		to = p.To
+		return nil
+	}))
+
+	if err := reg.Dispatch(context.Background(), Job{Type: "email.send", Payload: []byte(`{"to":"a@b.com"}`)}); err != nil {
+		t.Fatalf("dispatch error: %v", err)
+	}
+	if to != "a@b.com" {
+		t.Fatalf("expected decoded payload, got %q", to)
+	}
+
+	err := reg.Dispatch(context.Background(), Job{Type: "email.send", Payload: []byte(`[1,2]`)})
+	if got := retry.ClassifyError(err); got != retry.ClassTerminal {
+		t.Fatalf("expected terminal for bad payload, got %s (%v)", got, err)
+	}
+}
+
+func TestLoopRunDispatchesLeasedJobAndDLQsUnknownType(t *testing.T) {
+	store := newFakeLeaseStore()
+	store.jobType = "missing"
+	loop := NewLoop(store, "jobs:ready", "lease-1", 50*time.Millisecond)
+	loop.pollInterval = 5 * time.Millisecond
+	reg := NewRegistry()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := loop.Run(ctx, reg.Dispatch); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	if store.terminalCount != 1 {
+		t.Fatalf("expected unknown job type to be marked terminal, got terminalCount=%d", store.terminalCount)
+	}
+}
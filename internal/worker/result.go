@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MaxResultBytes caps the encoded size of a job result.
+const MaxResultBytes = 64 << 10
+
+const defaultResultContentType = "application/json"
+
+var (
+	ErrResultTooLarge = errors.New("job result too large")
+	ErrNoResultSlot   = errors.New("context does not belong to a running job")
+)
+
+// Result is the JSON output of a successful job.
+type Result struct {
+	Data        json.RawMessage
+	ContentType string
+}
+
+type resultKey struct{}
+
+// resultSlot holds the result a handler sets until the job is marked
+// succeeded.
+type resultSlot struct {
+	mu     sync.Mutex
+	result *Result
+}
+
+func withResultSlot(ctx context.Context) (context.Context, *resultSlot) {
+	slot := &resultSlot{}
+	return context.WithValue(ctx, resultKey{}, slot), slot
+}
+
+func (s *resultSlot) get() *Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.result
+}
+
+// SetResult encodes v as JSON and stores it as the job's result once the
+// handler returns without error. A later call replaces the result. Results
+// larger than MaxResultBytes are rejected with ErrResultTooLarge.
+func SetResult(ctx context.Context, v any) error {
+	return SetResultWithContentType(ctx, v, "")
+}
+
+// SetResultWithContentType is SetResult with the content type GET
+// /jobs/{id}/result responds with, e.g. a vendor JSON type. An empty type
+// means application/json.
+func SetResultWithContentType(ctx context.Context, v any, contentType string) error {
+	slot, ok := ctx.Value(resultKey{}).(*resultSlot)
+	if !ok {
+		return ErrNoResultSlot
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode job result: %w", err)
+	}
+	if len(data) > MaxResultBytes {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrResultTooLarge, len(data), MaxResultBytes)
+	}
+	if contentType == "" {
+		contentType = defaultResultContentType
+	}
+
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	slot.result = &Result{Data: data, ContentType: contentType}
+	return nil
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessOneStoresHandlerResult(t *testing.T) {
+	store := newFakeLeaseStore()
+	lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), time.Second)
+	if err != nil || !ok {
+		t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
+	}
+	loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+
+	err = loop.ProcessOne(context.Background(), Job{ID: "job-1", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error {
+		if err := SetResult(ctx, map[string]int{"sent": 1}); err != nil {
+			return err
+		}
+		return SetResultWithContentType(ctx, map[string]int{"sent": 2}, "application/vnd.taskforge.receipt+json")
+	})
+	if err != nil {
+		t.Fatalf("ProcessOne error: %v", err)
+	}
+	if store.result == nil {
+		t.Fatal("expected result stored on success")
+	}
+	if string(store.result.Data) != `{"sent":2}` || store.result.ContentType != "application/vnd.taskforge.receipt+json" {
+		t.Fatalf("expected the last result to win, got %s %q", store.result.Data, store.result.ContentType)
+	}
+}
+
+func TestSetResultRejectsOversizedResults(t *testing.T) {
+	ctx, slot := withResultSlot(context.Background())
+	err := SetResult(ctx, strings.Repeat("x", MaxResultBytes))
+	if !errors.Is(err, ErrResultTooLarge) {
+		t.Fatalf("expected ErrResultTooLarge, got %v", err)
+	}
+	if slot.get() != nil {
+		t.Fatal("expected oversized result to be dropped")
+	}
+
+	if err := SetResult(ctx, "ok"); err != nil {
+		t.Fatalf("SetResult error: %v", err)
+	}
+	if got := slot.get(); got == nil || got.ContentType != "application/json" {
+		t.Fatalf("expected default content type, got %+v", got)
+	}
+}
+
+func TestSetResultOutsideJob(t *testing.T) {
+	if err := SetResult(context.Background(), "ok"); !errors.Is(err, ErrNoResultSlot) {
+		t.Fatalf("expected ErrNoResultSlot, got %v", err)
+	}
+}
@@ -2,22 +2,49 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
 	"time"
 
 	"github.com/pranavko12/taskforge/internal/metrics"
+	"github.com/pranavko12/taskforge/internal/retry"
 )
 
 type Runner struct {
-	throttler *Throttler
-	queueName string
-	store     LeaseStore
+	throttler   *Throttler
+	queueName   string
+	store       LeaseStore
+	retryPanics bool
 }
 
 func NewRunner(queueName string, throttler *Throttler, store LeaseStore) *Runner {
 	return &Runner{queueName: queueName, throttler: throttler, store: store}
 }
 
+// PanicError is the failure of a handler that panicked. Its message carries
+// the panic value and the handler's stack, so both are kept in last_error and
+// the attempt history.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// SetRetryPanics makes a handler panic a retryable failure instead of a
+// terminal one.
+func (r *Runner) SetRetryPanics(enabled bool) {
+	r.retryPanics = enabled
+}
+
 func (r *Runner) Execute(ctx context.Context, fn func(context.Context) error) error {
+	return r.execute(ctx, "", fn)
+}
+
+func (r *Runner) execute(ctx context.Context, jobType string, fn func(context.Context) error) error {
 	if r.throttler != nil {
 		if err := r.throttler.Acquire(ctx); err != nil {
 			return err
@@ -26,7 +53,7 @@ func (r *Runner) Execute(ctx context.Context, fn func(context.Context) error) er
 	}
 	metrics.IncAttempts(r.queueName)
 	start := time.Now()
-	err := fn(ctx)
+	err := r.call(ctx, jobType, fn)
 	metrics.ObserveRuntime(r.queueName, time.Since(start).Seconds())
 	if err != nil {
 		metrics.IncFailure(r.queueName)
@@ -36,20 +63,41 @@ func (r *Runner) Execute(ctx context.Context, fn func(context.Context) error) er
 	return nil
 }
 
+// call runs fn and turns a panic into a *PanicError, so one bad handler does
+// not take down the worker and orphan every other in-flight lease. Panics in
+// goroutines the handler starts cannot be recovered here.
+func (r *Runner) call(ctx context.Context, jobType string, fn func(context.Context) error) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		incJobPanics(r.queueName, jobType)
+		slog.Error("job handler panicked", "queue", r.queueName, "job_type", jobType, "panic", v)
+		err = &PanicError{Value: v, Stack: debug.Stack()}
+		if r.retryPanics {
+			err = retry.Retryable(err)
+		} else {
+			err = retry.Terminal(err)
+		}
+	}()
+	return fn(ctx)
+}
+
 func (r *Runner) ExecuteWithQueueTime(ctx context.Context, timeInQueue time.Duration, fn func(context.Context) error) error {
 	metrics.ObserveTimeInQueue(r.queueName, timeInQueue.Seconds())
 	return r.Execute(ctx, fn)
 }
 
-func (r *Runner) ExecuteJob(ctx context.Context, jobID string, timeInQueue time.Duration, fn func(context.Context) error) error {
+func (r *Runner) ExecuteJob(ctx context.Context, job Job, timeInQueue time.Duration, fn func(context.Context) error) error {
 	traceparent := ""
 	if r.store != nil {
-		if tp, err := r.store.GetTraceparent(ctx, jobID); err == nil {
+		if tp, err := r.store.GetTraceparent(ctx, job.ID); err == nil {
 			traceparent = tp
 		}
 	}
-	traceCtx, end := StartJobSpan(jobID, r.queueName, traceparent)
+	traceCtx, end := StartJobSpan(job.ID, r.queueName, traceparent)
 	defer end()
 	metrics.ObserveTimeInQueue(r.queueName, timeInQueue.Seconds())
-	return r.Execute(traceCtx, fn)
+	return r.execute(traceCtx, job.Type, fn)
 }
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunnerRecoversHandlerPanic(t *testing.T) {
+	for _, retryPanics := range []bool{false, true} {
+		store := newFakeLeaseStore()
+		lease, ok, err := store.AcquireLease(context.Background(), "job-1", "lease-1", time.Now().UTC(), time.Second)
+		if err != nil || !ok {
+			t.Fatalf("acquire lease failed: %v ok=%v", err, ok)
+		}
+		loop := NewLoop(store, "jobs:ready", "lease-1", time.Second)
+		runner := NewRunner("jobs:ready", nil, nil)
+		runner.SetRetryPanics(retryPanics)
+
+		var runErr error
+		err = loop.ProcessOne(context.Background(), Job{ID: "job-1", Type: "demo", LeaseToken: lease.Token}, func(ctx context.Context, job Job) error {
+			runErr = runner.ExecuteJob(ctx, job, 0, func(context.Context) error {
+				panic("boom")
+			})
+			return runErr
+		})
+		if err != nil {
+			t.Fatalf("process one returned error: %v", err)
+		}
+
+		var panicErr *PanicError
+		if !errors.As(runErr, &panicErr) || panicErr.Value != "boom" {
+			t.Fatalf("expected a PanicError for boom, got %v", runErr)
+		}
+		if !strings.HasPrefix(store.lastError, "panic: boom") || !strings.Contains(store.lastError, "runner_test.go") {
+			t.Fatalf("expected the panic and its stack in last_error, got %q", store.lastError)
+		}
+		if retryPanics && (store.failedCount != 1 || store.terminalCount != 0) {
+			t.Fatalf("expected a retryable failure, got failed=%d terminal=%d", store.failedCount, store.terminalCount)
+		}
+		if !retryPanics && (store.failedCount != 0 || store.terminalCount != 1) {
+			t.Fatalf("expected a terminal failure, got failed=%d terminal=%d", store.failedCount, store.terminalCount)
+		}
+	}
+}
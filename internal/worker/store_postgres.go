@@ -3,109 +3,252 @@ package worker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/pranavko12/taskforge/internal/retry"
+	"github.com/pranavko12/taskforge/internal/storage"
+)
+
+const (
+	MaxPriority = 9
+
+	DefaultPriorityAging = time.Minute
+
+	// leaseHeadsPerPriority is how many of the oldest ready jobs of each
+	// priority LeaseNextJob weighs against each other. It only needs to
+	// exceed the number of workers leasing from one queue at the same moment,
+	// since rows they hold are skipped.
+	leaseHeadsPerPriority = 16
+)
+
+// Attempt outcomes recorded in job_attempts.
+const (
+	AttemptRunning      = "running"
+	AttemptSucceeded    = "succeeded"
+	AttemptFailed       = "failed"
+	AttemptTerminal     = "terminal"
+	AttemptLeaseExpired = "lease_expired"
+	AttemptCanceled     = "canceled"
 )
 
+// ReasonLeaseExpiredRepeatedly is the dead-letter reason of a job the reaper
+// gave up on because its lease kept expiring.
+const ReasonLeaseExpiredRepeatedly = "lease_expired_repeatedly"
+
 type PostgresStore struct {
-	pool *pgxpool.Pool
+	pool          *pgxpool.Pool
+	priorityAging time.Duration
 }
 
 func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
-	return &PostgresStore{pool: pool}
+	return &PostgresStore{pool: pool, priorityAging: DefaultPriorityAging}
+}
+
+// SetPriorityAging sets how long a ready job waits before it is leased as if
+// its priority were one higher, up to MaxPriority. Zero disables aging.
+func (s *PostgresStore) SetPriorityAging(d time.Duration) {
+	s.priorityAging = d
 }
 
-func (s *PostgresStore) LeaseNextJob(ctx context.Context, queueName string, leaseID string, now time.Time, leaseFor time.Duration) (string, bool, error) {
-	var jobID string
+// LeaseNextJob leases the ready job with the highest aged priority.
+// Aging is computed per row, so it cannot be sorted by an index. Within one
+// priority the oldest job is always aged the furthest, so the heads CTE reads
+// only the oldest few of each priority through jobs_queue_pending_priority_idx
+// and the aging sort runs over those instead of the whole backlog.
+func (s *PostgresStore) LeaseNextJob(ctx context.Context, queueName string, owner string, now time.Time, leaseFor time.Duration) (Job, bool, error) {
+	var job Job
+	var timeoutMs int64
 	err := s.pool.QueryRow(ctx, `
-		WITH candidate AS (
-			SELECT job_id
-			FROM jobs
-			WHERE queue_name = $1
-				AND state = 'PENDING'
-				AND next_run_at <= $2
-			ORDER BY next_run_at ASC, created_at ASC
-			FOR UPDATE SKIP LOCKED
+		WITH heads AS (
+			SELECT h.job_id, h.priority, h.next_run_at, h.created_at
+			FROM generate_series(0, $6::int) AS level
+			CROSS JOIN LATERAL (
+				SELECT job_id, priority, next_run_at, created_at
+				FROM jobs
+				WHERE queue_name = $1
+					AND state = 'PENDING'
+					AND priority = level
+					AND next_run_at <= $2
+				ORDER BY next_run_at ASC
+				LIMIT $7
+			) h
+		), candidate AS (
+			SELECT j.job_id
+			FROM heads
+			JOIN jobs j ON j.job_id = heads.job_id
+			WHERE j.state = 'PENDING'
+				AND j.next_run_at <= $2
+			ORDER BY
+				CASE WHEN $5::float8 > 0
+					THEN LEAST($6::int, heads.priority + FLOOR(EXTRACT(EPOCH FROM ($2 - heads.next_run_at)) / $5::float8))
+					ELSE heads.priority
+				END DESC,
+				heads.next_run_at ASC,
+				heads.created_at ASC
+			FOR UPDATE OF j SKIP LOCKED
 			LIMIT 1
+		), leased AS (
+			UPDATE jobs j
+			SET state = 'IN_PROGRESS',
+				attempt_count = attempt_count + 1,
+				lease_owner = $3,
+				lease_token = lease_token + 1,
+				lease_expires_at = $4,
+				started_at = COALESCE(started_at, $2),
+				cancel_requested_at = NULL,
+				cancel_reason = '',
+				updated_at = NOW()
+			FROM candidate
+			WHERE j.job_id = candidate.job_id
+			RETURNING j.job_id, j.queue_name, j.job_type, j.payload, j.attempt_count, j.priority, j.next_run_at, j.lease_token, j.timeout_ms
+		), attempt AS (
+			INSERT INTO job_attempts (job_id, attempt, lease_owner, lease_token)
+			SELECT job_id, attempt_count, $3, lease_token
+			FROM leased
 		)
-		UPDATE jobs j
-		SET state = 'IN_PROGRESS',
-			attempt_count = attempt_count + 1,
-			lease_owner = $3,
-			lease_expires_at = $4,
-			started_at = COALESCE(started_at, $2),
-			updated_at = NOW()
-		FROM candidate
-		WHERE j.job_id = candidate.job_id
-		RETURNING j.job_id
-	`, queueName, now, leaseID, now.Add(leaseFor)).Scan(&jobID)
+		SELECT job_id, queue_name, job_type, payload, attempt_count, priority, next_run_at, lease_token, timeout_ms
+		FROM leased
+	`, queueName, now, owner, now.Add(leaseFor), s.priorityAging.Seconds(), MaxPriority, leaseHeadsPerPriority).Scan(
+		&job.ID, &job.Queue, &job.Type, &job.Payload, &job.Attempt, &job.Priority, &job.ReadyAt, &job.LeaseToken, &timeoutMs,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", false, nil
+			return Job{}, false, nil
 		}
-		return "", false, err
+		return Job{}, false, err
 	}
-	return jobID, true, nil
+	job.Timeout = time.Duration(timeoutMs) * time.Millisecond
+	return job, true, nil
 }
 
-func (s *PostgresStore) AcquireLease(ctx context.Context, jobID string, owner string, now time.Time, leaseFor time.Duration) (bool, error) {
-	tag, err := s.pool.Exec(ctx, `
-		UPDATE jobs
-		SET state = 'IN_PROGRESS',
-			attempt_count = attempt_count + 1,
-			lease_owner = $1,
-			lease_expires_at = $2,
-			started_at = COALESCE(started_at, NOW()),
-			updated_at = NOW()
-		WHERE job_id = $3
-			AND state = 'PENDING'
-			AND (lease_expires_at IS NULL OR lease_expires_at <= $4)
-	`, owner, now.Add(leaseFor), jobID, now)
+func (s *PostgresStore) AcquireLease(ctx context.Context, jobID string, owner string, now time.Time, leaseFor time.Duration) (Lease, bool, error) {
+	// The attempt row is inserted only when the lease is taken, so it
+	// returning a token reports whether it was.
+	lease := Lease{Owner: owner}
+	err := s.pool.QueryRow(ctx, `
+		WITH leased AS (
+			UPDATE jobs
+			SET state = 'IN_PROGRESS',
+				attempt_count = attempt_count + 1,
+				lease_owner = $1,
+				lease_token = lease_token + 1,
+				lease_expires_at = $2,
+				started_at = COALESCE(started_at, NOW()),
+				cancel_requested_at = NULL,
+				cancel_reason = '',
+				updated_at = NOW()
+			WHERE job_id = $3
+				AND state = 'PENDING'
+				AND (lease_expires_at IS NULL OR lease_expires_at <= $4)
+			RETURNING job_id, attempt_count, lease_token
+		)
+		INSERT INTO job_attempts (job_id, attempt, lease_owner, lease_token)
+		SELECT job_id, attempt_count, $1, lease_token
+		FROM leased
+		RETURNING lease_token
+	`, owner, now.Add(leaseFor), jobID, now).Scan(&lease.Token)
 	if err != nil {
-		return false, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Lease{}, false, nil
+		}
+		return Lease{}, false, err
 	}
-	return tag.RowsAffected() == 1, nil
+	return lease, true, nil
 }
 
-func (s *PostgresStore) RenewLease(ctx context.Context, jobID string, leaseID string, extendBy time.Duration) (bool, error) {
+func (s *PostgresStore) RenewLease(ctx context.Context, jobID string, lease Lease, extendBy time.Duration) (LeaseStatus, error) {
 	expiresAt := time.Now().UTC().Add(extendBy)
-	tag, err := s.pool.Exec(ctx, `
+	var status LeaseStatus
+	err := s.pool.QueryRow(ctx, `
 		UPDATE jobs
 		SET lease_expires_at = $1,
 			updated_at = NOW()
 		WHERE job_id = $2
 			AND state = 'IN_PROGRESS'
 			AND lease_owner = $3
-	`, expiresAt, jobID, leaseID)
+			AND lease_token = $4
+		RETURNING cancel_requested_at IS NOT NULL
+	`, expiresAt, jobID, lease.Owner, lease.Token).Scan(&status.CancelRequested)
 	if err != nil {
-		return false, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LeaseStatus{}, nil
+		}
+		return LeaseStatus{}, err
 	}
-	return tag.RowsAffected() == 1, nil
+	status.Held = true
+	return status, nil
 }
 
-func (s *PostgresStore) MarkJobSucceeded(ctx context.Context, jobID string, leaseID string) (bool, error) {
-	tag, err := s.pool.Exec(ctx, `
+func (s *PostgresStore) MarkJobSucceeded(ctx context.Context, jobID string, lease Lease, result *Result) (ok bool, err error) {
+	var data []byte
+	var contentType *string
+	if result != nil {
+		data = result.Data
+		contentType = &result.ContentType
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !ok {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.WorkerActor(lease.Owner), "succeeded"); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET state = 'COMPLETED',
 			lease_owner = NULL,
 			lease_expires_at = NULL,
 			last_error = '',
+			result = $3,
+			result_content_type = $4,
 			completed_at = NOW(),
 			updated_at = NOW()
 		WHERE job_id = $1
 			AND state = 'IN_PROGRESS'
 			AND lease_owner = $2
-	`, jobID, leaseID)
+			AND lease_token = $5
+	`, jobID, lease.Owner, data, contentType, lease.Token)
 	if err != nil {
 		return false, err
 	}
-	return tag.RowsAffected() == 1, nil
+	if tag.RowsAffected() != 1 {
+		return false, nil
+	}
+	if err = finishAttempt(ctx, tx, jobID, lease, AttemptSucceeded, "", ""); err != nil {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (s *PostgresStore) MarkJobFailed(ctx context.Context, jobID string, leaseID string, lastError string) (bool, error) {
-	tag, err := s.pool.Exec(ctx, `
+func (s *PostgresStore) MarkJobFailed(ctx context.Context, jobID string, lease Lease, lastError string, class retry.FailureClass) (ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !ok {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = storage.SetEventContext(ctx, tx, storage.WorkerActor(lease.Owner), lastError); err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET state = 'FAILED',
 			lease_owner = NULL,
@@ -115,14 +258,33 @@ func (s *PostgresStore) MarkJobFailed(ctx context.Context, jobID string, leaseID
 		WHERE job_id = $1
 			AND state = 'IN_PROGRESS'
 			AND lease_owner = $2
-	`, jobID, leaseID, lastError)
+			AND lease_token = $4
+			AND cancel_requested_at IS NULL
+	`, jobID, lease.Owner, lastError, lease.Token)
 	if err != nil {
 		return false, err
 	}
-	return tag.RowsAffected() == 1, nil
+	if tag.RowsAffected() != 1 {
+		// A pending cancel request wins over the failure; otherwise the
+		// lease was lost.
+		if ok, err = cancelLeasedJob(ctx, tx, jobID, lease); err != nil || !ok {
+			return false, err
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err = finishAttempt(ctx, tx, jobID, lease, AttemptFailed, lastError, class); err != nil {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (s *PostgresStore) MarkJobTerminal(ctx context.Context, jobID string, leaseID string, lastError string) (bool, error) {
+func (s *PostgresStore) MarkJobTerminal(ctx context.Context, jobID string, lease Lease, lastError string) (bool, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return false, err
@@ -133,6 +295,9 @@ func (s *PostgresStore) MarkJobTerminal(ctx context.Context, jobID string, lease
 		}
 	}()
 
+	if err = storage.SetEventContext(ctx, tx, storage.WorkerActor(lease.Owner), lastError); err != nil {
+		return false, err
+	}
 	tag, err := tx.Exec(ctx, `
 		UPDATE jobs
 		SET state = 'DLQ',
@@ -143,50 +308,110 @@ func (s *PostgresStore) MarkJobTerminal(ctx context.Context, jobID string, lease
 		WHERE job_id = $1
 			AND state = 'IN_PROGRESS'
 			AND lease_owner = $2
-	`, jobID, leaseID, lastError)
+			AND lease_token = $4
+			AND cancel_requested_at IS NULL
+	`, jobID, lease.Owner, lastError, lease.Token)
 	if err != nil {
 		return false, err
 	}
 	if tag.RowsAffected() != 1 {
-		err = tx.Commit(ctx)
-		if err != nil {
+		// A canceled job stays out of the DLQ.
+		var canceled bool
+		if canceled, err = cancelLeasedJob(ctx, tx, jobID, lease); err != nil {
 			return false, err
 		}
-		return false, nil
+		if err = tx.Commit(ctx); err != nil {
+			return false, err
+		}
+		return canceled, nil
+	}
+
+	if err = finishAttempt(ctx, tx, jobID, lease, AttemptTerminal, lastError, retry.ClassTerminal); err != nil {
+		return false, err
 	}
 
 	reason := lastError
 	if reason == "" {
 		reason = "terminal failure"
 	}
-	if _, err = tx.Exec(ctx, `
-		INSERT INTO dead_letters (
-			job_id, queue_name, job_type, payload, reason, last_error, attempts, failed_at, created_at, updated_at
-		)
-		SELECT
-			job_id, queue_name, job_type, payload, $2, COALESCE(last_error, ''), attempt_count, NOW(), NOW(), NOW()
+	if err = insertDeadLetter(ctx, tx, jobID, reason); err != nil {
+		return false, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *PostgresStore) MarkJobCanceled(ctx context.Context, jobID string, lease Lease) (ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !ok {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if ok, err = cancelLeasedJob(ctx, tx, jobID, lease); err != nil || !ok {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// cancelLeasedJob moves the job lease holds to CANCELED if cancellation was
+// requested, recording the request's reason. It reports whether it did.
+func cancelLeasedJob(ctx context.Context, tx pgx.Tx, jobID string, lease Lease) (bool, error) {
+	var reason string
+	err := tx.QueryRow(ctx, `
+		SELECT cancel_reason
 		FROM jobs
 		WHERE job_id = $1
-		ON CONFLICT (job_id) DO UPDATE
-		SET reason = EXCLUDED.reason,
-			last_error = EXCLUDED.last_error,
-			attempts = EXCLUDED.attempts,
-			failed_at = EXCLUDED.failed_at,
+			AND state = 'IN_PROGRESS'
+			AND lease_owner = $2
+			AND lease_token = $3
+			AND cancel_requested_at IS NOT NULL
+		FOR UPDATE
+	`, jobID, lease.Owner, lease.Token).Scan(&reason)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if reason == "" {
+		reason = "canceled"
+	}
+
+	if err := storage.SetEventContext(ctx, tx, storage.WorkerActor(lease.Owner), reason); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE jobs
+		SET state = 'CANCELED',
+			lease_owner = NULL,
+			lease_expires_at = NULL,
+			last_error = $2,
 			updated_at = NOW()
+		WHERE job_id = $1
 	`, jobID, reason); err != nil {
 		return false, err
 	}
-
-	err = tx.Commit(ctx)
-	if err != nil {
+	if err := finishAttempt(ctx, tx, jobID, lease, AttemptCanceled, reason, ""); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (s *PostgresStore) ListExpiredLeases(ctx context.Context, now time.Time, limit int) ([]string, error) {
+func (s *PostgresStore) ListExpiredLeases(ctx context.Context, now time.Time, limit int) ([]ExpiredLease, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT job_id
+		SELECT job_id, queue_name, job_type
 		FROM jobs
 		WHERE state = 'IN_PROGRESS'
 			AND lease_expires_at IS NOT NULL
@@ -199,30 +424,134 @@ func (s *PostgresStore) ListExpiredLeases(ctx context.Context, now time.Time, li
 	}
 	defer rows.Close()
 
-	var ids []string
+	var expired []ExpiredLease
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var lease ExpiredLease
+		if err := rows.Scan(&lease.JobID, &lease.QueueName, &lease.JobType); err != nil {
 			return nil, err
 		}
-		ids = append(ids, id)
+		expired = append(expired, lease)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return ids, nil
+	return expired, nil
 }
 
-func (s *PostgresStore) ResetLease(ctx context.Context, jobID string) error {
-	_, err := s.pool.Exec(ctx, `
-		UPDATE jobs
-		SET state = 'PENDING',
-			lease_owner = NULL,
-			lease_expires_at = NULL,
-			available_at = NOW(),
-			updated_at = NOW()
+func (s *PostgresStore) ResetLease(ctx context.Context, jobID string, maxExpirations int) (outcome ResetOutcome, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return ResetSkipped, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var queueName string
+	var expirations, maxAttempts int
+	var cancelRequested bool
+	err = tx.QueryRow(ctx, `
+		SELECT queue_name, lease_expirations + 1, max_attempts, cancel_requested_at IS NOT NULL
+		FROM jobs
+		WHERE job_id = $1 AND state = 'IN_PROGRESS'
+		FOR UPDATE
+	`, jobID).Scan(&queueName, &expirations, &maxAttempts, &cancelRequested)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The worker reported an outcome after all.
+			_ = tx.Rollback(ctx)
+			return ResetSkipped, nil
+		}
+		return ResetSkipped, err
+	}
+
+	state, reason, lastError := "PENDING", "lease expired", ""
+	outcome = ResetRequeued
+	switch {
+	case cancelRequested:
+		state, outcome = "CANCELED", ResetCanceled
+	case (maxExpirations > 0 && expirations >= maxExpirations) || expirations >= maxAttempts:
+		state, reason, outcome = "DLQ", ReasonLeaseExpiredRepeatedly, ResetPoisoned
+		lastError = fmt.Sprintf("lease expired %d times", expirations)
+	}
+
+	if err = storage.SetEventContext(ctx, tx, storage.ActorReaper, reason); err != nil {
+		return ResetSkipped, err
+	}
+	_, err = tx.Exec(ctx, `
+		WITH reset AS (
+			UPDATE jobs
+			SET state = $3::job_state,
+				lease_expirations = lease_expirations + 1,
+				lease_owner = NULL,
+				lease_expires_at = NULL,
+				last_error = CASE WHEN $4::text = '' THEN last_error ELSE $4::text END,
+				available_at = NOW(),
+				updated_at = NOW()
+			WHERE job_id = $1
+			RETURNING job_id
+		)
+		UPDATE job_attempts a
+		SET outcome = $2,
+			finished_at = NOW(),
+			duration_ms = `+attemptDurationSQL+`,
+			error = 'lease expired'
+		FROM reset
+		WHERE a.job_id = reset.job_id AND a.finished_at IS NULL
+	`, jobID, AttemptLeaseExpired, state, lastError)
+	if err != nil {
+		return ResetSkipped, err
+	}
+	if outcome == ResetPoisoned {
+		if err = insertDeadLetter(ctx, tx, jobID, ReasonLeaseExpiredRepeatedly); err != nil {
+			return ResetSkipped, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return ResetSkipped, err
+	}
+	if outcome == ResetRequeued {
+		storage.Notify(ctx, s.pool, queueName)
+	}
+	return outcome, nil
+}
+
+// insertDeadLetter records jobID, already moved to DLQ, in dead_letters.
+func insertDeadLetter(ctx context.Context, tx pgx.Tx, jobID string, reason string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO dead_letters (
+			job_id, queue_name, job_type, payload, reason, last_error, attempts, failed_at, created_at, updated_at
+		)
+		SELECT
+			job_id, queue_name, job_type, payload, $2, COALESCE(last_error, ''), attempt_count, NOW(), NOW(), NOW()
+		FROM jobs
 		WHERE job_id = $1
-	`, jobID)
+		ON CONFLICT (job_id) DO UPDATE
+		SET reason = EXCLUDED.reason,
+			last_error = EXCLUDED.last_error,
+			attempts = EXCLUDED.attempts,
+			failed_at = EXCLUDED.failed_at,
+			updated_at = NOW()
+	`, jobID, reason)
+	return err
+}
+
+// attemptDurationSQL computes an attempt's duration as it is finished.
+const attemptDurationSQL = `GREATEST(0, FLOOR(EXTRACT(EPOCH FROM (NOW() - started_at)) * 1000))::bigint`
+
+// finishAttempt closes the attempt lease opened on jobID.
+func finishAttempt(ctx context.Context, tx pgx.Tx, jobID string, lease Lease, outcome string, lastError string, class retry.FailureClass) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE job_attempts
+		SET outcome = $3,
+			finished_at = NOW(),
+			duration_ms = `+attemptDurationSQL+`,
+			error = $4,
+			failure_class = $5
+		WHERE job_id = $1 AND lease_owner = $2 AND lease_token = $6 AND finished_at IS NULL
+	`, jobID, lease.Owner, outcome, lastError, string(class), lease.Token)
 	return err
 }
 
@@ -231,3 +560,18 @@ func (s *PostgresStore) GetTraceparent(ctx context.Context, jobID string) (strin
 	err := s.pool.QueryRow(ctx, `SELECT COALESCE(traceparent, '') FROM jobs WHERE job_id = $1`, jobID).Scan(&traceparent)
 	return traceparent, err
 }
+
+func (s *PostgresStore) RecordWebhookResponse(ctx context.Context, jobID string, leaseToken int64, status int, excerpt string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE jobs
+		SET last_response_status = $2,
+			last_response_excerpt = $3,
+			updated_at = NOW()
+		WHERE job_id = $1
+			AND lease_token = $4
+	`, jobID, status, excerpt, leaseToken)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
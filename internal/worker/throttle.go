@@ -2,6 +2,8 @@ package worker
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pranavko12/taskforge/internal/metrics"
@@ -14,7 +16,12 @@ type Throttler struct {
 	interval   time.Duration
 	stopTokens chan struct{}
 	capacity   int
-	inFlight   int
+
+	mu       sync.Mutex
+	inFlight int
+
+	// deferred is set when TryAcquire refuses a slot and cleared by settle.
+	deferred atomic.Bool
 }
 
 func NewThrottler(queueName string, concurrency int, ratePerSec int) *Throttler {
@@ -89,18 +96,56 @@ func (t *Throttler) Acquire(ctx context.Context) error {
 		}
 	}
 	if t.capacity > 0 {
+		t.mu.Lock()
 		t.inFlight++
 		metrics.SetWorkerUtilization(t.queueName, float64(t.inFlight)/float64(t.capacity))
+		t.mu.Unlock()
 	}
 	return nil
 }
 
+// TryAcquire takes a concurrency slot without waiting and reports whether one
+// was free. It does not consume rate-limit tokens. Callers that get true must
+// call Release, and settle once they know whether the queue had a job.
+// A refusal is not counted on its own, since an idle queue at its cap is
+// refused on every poll; settle counts it if a job turns out to be waiting.
+func (t *Throttler) TryAcquire() bool {
+	if t.sem != nil {
+		select {
+		case <-t.sem:
+		default:
+			t.deferred.Store(true)
+			return false
+		}
+	}
+	if t.capacity > 0 {
+		t.mu.Lock()
+		t.inFlight++
+		metrics.SetWorkerUtilization(t.queueName, float64(t.inFlight)/float64(t.capacity))
+		t.mu.Unlock()
+	}
+	return true
+}
+
+// settle records the result of the first lease attempt after TryAcquire was
+// refused. A leased job was held back by the cap, so the refusals count as
+// one concurrency throttle; an empty queue means nothing was deferred.
+func (t *Throttler) settle(leased bool) {
+	if t.deferred.Swap(false) && leased {
+		incConcurrencyThrottled(t.queueName)
+	}
+}
+
 func (t *Throttler) Release() {
 	if t.sem != nil {
 		t.sem <- struct{}{}
 	}
-	if t.capacity > 0 && t.inFlight > 0 {
-		t.inFlight--
+	if t.capacity > 0 {
+		t.mu.Lock()
+		if t.inFlight > 0 {
+			t.inFlight--
+		}
 		metrics.SetWorkerUtilization(t.queueName, float64(t.inFlight)/float64(t.capacity))
+		t.mu.Unlock()
 	}
 }
@@ -54,3 +54,48 @@ func TestRateLimitBlocks(t *testing.T) {
 		t.Fatalf("expected rate limit wait")
 	}
 }
+
+func TestTryAcquireDoesNotBlock(t *testing.T) {
+	tl := NewThrottler("jobs:ready", 1, 0)
+	defer tl.Close()
+
+	if !tl.TryAcquire() {
+		t.Fatal("expected first TryAcquire to succeed")
+	}
+	if tl.TryAcquire() {
+		t.Fatal("expected TryAcquire to fail at capacity")
+	}
+	tl.Release()
+	if !tl.TryAcquire() {
+		t.Fatal("expected TryAcquire to succeed after release")
+	}
+	tl.Release()
+}
+
+func TestTryAcquireCountsOnlyDeferredJobs(t *testing.T) {
+	tl := NewThrottler("jobs:ready", 1, 0)
+	defer tl.Close()
+
+	if !tl.TryAcquire() {
+		t.Fatal("expected first TryAcquire to succeed")
+	}
+	tl.settle(true)
+	if tl.deferred.Load() {
+		t.Fatal("expected no deferral before the cap was hit")
+	}
+	for i := 0; i < 3; i++ {
+		if tl.TryAcquire() {
+			t.Fatal("expected TryAcquire to fail at capacity")
+		}
+	}
+	tl.Release()
+	if !tl.TryAcquire() {
+		t.Fatal("expected TryAcquire to succeed after release")
+	}
+	// The queue was empty, so the refusals were idle polls.
+	tl.settle(false)
+	if tl.deferred.Load() {
+		t.Fatal("expected settle to clear the deferral")
+	}
+	tl.Release()
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pranavko12/taskforge/internal/retry"
+)
+
+const (
+	WebhookDeliverJobType = "webhook.deliver"
+
+	webhookExcerptLimit = 1024
+	webhookTimeout      = 30 * time.Second
+)
+
+// WebhookDeliverPayload mirrors the payload validated by the API on submission.
+type WebhookDeliverPayload struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type WebhookResponseRecorder interface {
+	// RecordWebhookResponse stores the response on the job if leaseToken is
+	// still its current lease, and reports whether it did.
+	RecordWebhookResponse(ctx context.Context, jobID string, leaseToken int64, status int, excerpt string) (bool, error)
+}
+
+type WebhookExecutor struct {
+	client   *http.Client
+	recorder WebhookResponseRecorder
+}
+
+// NewWebhookExecutor builds the webhook.deliver executor. A nil client gets a
+// default with a request timeout that does not follow redirects.
+func NewWebhookExecutor(client *http.Client, recorder WebhookResponseRecorder) *WebhookExecutor {
+	if client == nil {
+		client = &http.Client{
+			Timeout: webhookTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return &WebhookExecutor{client: client, recorder: recorder}
+}
+
+// Handler returns the registry handler for webhook.deliver jobs.
+func (e *WebhookExecutor) Handler() Handler {
+	return HandleJSON(e.Deliver)
+}
+
+// Deliver performs the HTTP call. 5xx, 429 and network errors are retryable;
+// any other non-2xx response is terminal.
+func (e *WebhookExecutor) Deliver(ctx context.Context, job Job, p WebhookDeliverPayload) error {
+	method := strings.ToUpper(strings.TrimSpace(p.Method))
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSpace(p.URL), bytes.NewReader(p.Body))
+	if err != nil {
+		return retry.Terminal(fmt.Errorf("build webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range p.Headers {
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return retry.Retryable(fmt.Errorf("webhook request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, webhookExcerptLimit))
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if e.recorder != nil {
+		ok, err := e.recorder.RecordWebhookResponse(ctx, job.ID, job.LeaseToken, resp.StatusCode, string(excerpt))
+		if err != nil {
+			slog.Warn("record webhook response failed", "job_id", job.ID, "err", err)
+		} else if !ok {
+			slog.Warn("webhook response not recorded, lease lost", "job_id", job.ID, "lease_token", job.LeaseToken)
+		}
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retry.Retryable(fmt.Errorf("webhook responded %d", resp.StatusCode))
+	default:
+		return retry.Terminal(fmt.Errorf("webhook responded %d", resp.StatusCode))
+	}
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pranavko12/taskforge/internal/retry"
+)
+
+func TestWebhookDeliverSendsRequestAndRecordsResponse(t *testing.T) {
+	var gotMethod, gotHeader, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Get("X-Signature")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"received":true}`))
+	}))
+	defer srv.Close()
+
+	rec := &fakeWebhookRecorder{}
+	exec := NewWebhookExecutor(nil, rec)
+	job := webhookJob(t, WebhookDeliverPayload{
+		URL:     srv.URL,
+		Method:  "put",
+		Headers: map[string]string{"X-Signature": "abc"},
+		Body:    json.RawMessage(`{"event":"ping"}`),
+	})
+
+	if err := exec.Handler()(context.Background(), job); err != nil {
+		t.Fatalf("deliver error: %v", err)
+	}
+	if gotMethod != http.MethodPut || gotHeader != "abc" || gotBody != `{"event":"ping"}` {
+		t.Fatalf("unexpected request: method=%s header=%q body=%q", gotMethod, gotHeader, gotBody)
+	}
+	if rec.jobID != "job-1" || rec.leaseToken != 3 || rec.status != http.StatusCreated || rec.excerpt != `{"received":true}` {
+		t.Fatalf("unexpected recorded response: %+v", rec)
+	}
+}
+
+func TestWebhookDeliverClassifiesStatusCodes(t *testing.T) {
+	cases := []struct {
+		status int
+		want   retry.FailureClass
+	}{
+		{http.StatusInternalServerError, retry.ClassRetryable},
+		{http.StatusBadGateway, retry.ClassRetryable},
+		{http.StatusTooManyRequests, retry.ClassRetryable},
+		{http.StatusBadRequest, retry.ClassTerminal},
+		{http.StatusNotFound, retry.ClassTerminal},
+		{http.StatusFound, retry.ClassTerminal},
+	}
+
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.status == http.StatusFound {
+				w.Header().Set("Location", "/elsewhere")
+			}
+			w.WriteHeader(c.status)
+			_, _ = w.Write([]byte(strings.Repeat("x", 4096)))
+		}))
+		rec := &fakeWebhookRecorder{}
+		err := NewWebhookExecutor(nil, rec).Handler()(context.Background(), webhookJob(t, WebhookDeliverPayload{
+			URL:  srv.URL,
+			Body: json.RawMessage(`{}`),
+		}))
+		srv.Close()
+
+		if err == nil {
+			t.Fatalf("status %d: expected error", c.status)
+		}
+		if got := retry.ClassifyError(err); got != c.want {
+			t.Fatalf("status %d: expected %s, got %s", c.status, c.want, got)
+		}
+		if rec.status != c.status || len(rec.excerpt) != webhookExcerptLimit {
+			t.Fatalf("status %d: expected status and truncated excerpt recorded, got status=%d excerpt=%d bytes", c.status, rec.status, len(rec.excerpt))
+		}
+	}
+}
+
+func TestWebhookDeliverNetworkErrorIsRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	err := NewWebhookExecutor(nil, nil).Handler()(context.Background(), webhookJob(t, WebhookDeliverPayload{
+		URL:  url,
+		Body: json.RawMessage(`{}`),
+	}))
+	if got := retry.ClassifyError(err); got != retry.ClassRetryable {
+		t.Fatalf("expected retryable, got %s (%v)", got, err)
+	}
+}
+
+func webhookJob(t *testing.T, p WebhookDeliverPayload) Job {
+	t.Helper()
+	raw, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return Job{ID: "job-1", Type: WebhookDeliverJobType, Payload: raw, LeaseToken: 3}
+}
+
+type fakeWebhookRecorder struct {
+	jobID      string
+	leaseToken int64
+	status     int
+	excerpt    string
+}
+
+func (f *fakeWebhookRecorder) RecordWebhookResponse(ctx context.Context, jobID string, leaseToken int64, status int, excerpt string) (bool, error) {
+	f.jobID = jobID
+	f.leaseToken = leaseToken
+	f.status = status
+	f.excerpt = excerpt
+	return true, nil
+}
@@ -2,6 +2,9 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 )
 
@@ -21,31 +24,86 @@ func New(store LeaseStore, leaseID string, leaseFor time.Duration) *Worker {
 	}
 }
 
-func (w *Worker) Acquire(ctx context.Context, jobID string, now time.Time) (bool, error) {
+func (w *Worker) Acquire(ctx context.Context, jobID string, now time.Time) (Lease, bool, error) {
 	return w.store.AcquireLease(ctx, jobID, w.leaseID, now, w.leaseFor)
 }
 
-func (w *Worker) LeaseNext(ctx context.Context, queueName string, now time.Time) (string, bool, error) {
+func (w *Worker) LeaseNext(ctx context.Context, queueName string, now time.Time) (Job, bool, error) {
 	return w.store.LeaseNextJob(ctx, queueName, w.leaseID, now, w.leaseFor)
 }
 
-func (w *Worker) Renew(ctx context.Context, jobID string) (bool, error) {
-	return w.store.RenewLease(ctx, jobID, w.leaseID, w.leaseFor)
+func (w *Worker) Renew(ctx context.Context, job Job) (LeaseStatus, error) {
+	return w.store.RenewLease(ctx, job.ID, w.lease(job), w.leaseFor)
 }
 
-// Heartbeat renews the lease until ctx is cancelled.
-func (w *Worker) Heartbeat(ctx context.Context, jobID string) error {
+// lease is the lease this worker holds on job.
+func (w *Worker) lease(job Job) Lease {
+	return Lease{Owner: w.leaseID, Token: job.LeaseToken}
+}
+
+// ErrJobCanceled is the cause of a handler context canceled because the job
+// was canceled through the API. Handlers can check context.Cause.
+var ErrJobCanceled = errors.New("job canceled")
+
+// ErrJobTimeout is the cause of a handler context canceled because the job
+// ran past its execution timeout. The attempt fails as retry.ClassTimeout.
+var ErrJobTimeout = errors.New("job timed out")
+
+// ErrLeaseLost is the cause of a handler context canceled because the worker
+// no longer holds the job's lease, so another worker may already be running
+// it. The cause wraps it with the reason.
+var ErrLeaseLost = errors.New("lease lost")
+
+// maxRenewErrors is how many renewals in a row may fail before the lease is
+// treated as lost. Renewals run every half lease, so by the second failure
+// the lease has expired and the reaper may have handed the job out again.
+const maxRenewErrors = 2
+
+// Heartbeat renews the lease until ctx is cancelled. When a renewal reports
+// that cancellation was requested it calls cancel with ErrJobCanceled once,
+// and keeps renewing so the lease outlives the handler's cleanup. When the
+// lease is lost it calls cancel with an ErrLeaseLost error, stops renewing
+// and returns that error.
+func (w *Worker) Heartbeat(ctx context.Context, job Job, cancel context.CancelCauseFunc) error {
 	ticker := time.NewTicker(w.renewEvery)
 	defer ticker.Stop()
 
+	canceled := false
+	renewErrors := 0
 	for {
 		select {
 		case <-ticker.C:
-			if _, err := w.Renew(ctx, jobID); err != nil {
-				return err
+			status, err := w.Renew(ctx, job)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				renewErrors++
+				slog.Warn("lease renewal failed", "job_id", job.ID, "failures", renewErrors, "err", err)
+				if renewErrors < maxRenewErrors {
+					continue
+				}
+				return w.loseLease(job, "renew_failed", cancel,
+					fmt.Errorf("%w: %d renewals failed in a row: %v", ErrLeaseLost, renewErrors, err))
+			}
+			renewErrors = 0
+			if !status.Held {
+				return w.loseLease(job, "reclaimed", cancel,
+					fmt.Errorf("%w: lease expired or was reclaimed", ErrLeaseLost))
+			}
+			if status.CancelRequested && !canceled {
+				canceled = true
+				cancel(ErrJobCanceled)
 			}
 		case <-ctx.Done():
 			return nil
 		}
 	}
 }
+
+func (w *Worker) loseLease(job Job, reason string, cancel context.CancelCauseFunc, cause error) error {
+	slog.Warn("lease lost, canceling handler", "job_id", job.ID, "queue", job.Queue, "reason", reason, "err", cause)
+	incLeaseLost(job.Queue, reason)
+	cancel(cause)
+	return cause
+}
@@ -0,0 +1,3 @@
+ALTER TABLE jobs
+  ADD COLUMN last_response_status INT,
+  ADD COLUMN last_response_excerpt TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS schedules (
+  schedule_id UUID PRIMARY KEY,
+  name TEXT NOT NULL,
+  cron_expr TEXT NOT NULL,
+  timezone TEXT NOT NULL DEFAULT 'UTC',
+  queue_name TEXT NOT NULL,
+  job_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  max_attempts INT NOT NULL DEFAULT 5,
+  initial_delay INT NOT NULL DEFAULT 1000,
+  backoff DOUBLE PRECISION NOT NULL DEFAULT 2.0,
+  max_delay INT NOT NULL DEFAULT 60000,
+  jitter BOOLEAN NOT NULL DEFAULT FALSE,
+  missed_run_policy TEXT NOT NULL DEFAULT 'skip'
+    CHECK (missed_run_policy IN ('skip', 'run_once', 'catch_up')),
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  next_fire_at TIMESTAMPTZ NOT NULL,
+  last_fire_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS schedules_name_uq ON schedules (name);
+CREATE INDEX IF NOT EXISTS schedules_due_idx ON schedules (next_fire_at) WHERE enabled;
+
+COMMENT ON INDEX schedules_due_idx IS 'Scheduler scan for enabled schedules whose next fire time has passed.';
@@ -0,0 +1,13 @@
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0
+    CHECK (priority BETWEEN 0 AND 9);
+
+ALTER TABLE schedules
+  ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0
+    CHECK (priority BETWEEN 0 AND 9);
+
+CREATE INDEX IF NOT EXISTS jobs_queue_pending_priority_idx
+  ON jobs (queue_name, priority DESC, next_run_at ASC)
+  WHERE state = 'PENDING';
+
+COMMENT ON INDEX jobs_queue_pending_priority_idx IS 'Supports leasing ready jobs by priority, then next_run_at.';
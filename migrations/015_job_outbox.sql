@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS job_outbox (
+  outbox_id BIGSERIAL PRIMARY KEY,
+  job_id UUID NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+  queue_name TEXT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  published_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS job_outbox_unpublished_idx
+  ON job_outbox (next_attempt_at)
+  WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS job_outbox_job_idx
+  ON job_outbox (job_id)
+  WHERE published_at IS NULL;
+
+COMMENT ON TABLE job_outbox IS 'Queue publishes written in the same transaction as the job change; relayed to Redis by the scheduler.';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS job_attempts (
+  attempt_id BIGSERIAL PRIMARY KEY,
+  job_id UUID NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+  attempt INT NOT NULL,
+  lease_owner TEXT NOT NULL,
+  outcome TEXT NOT NULL DEFAULT 'running',
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ,
+  duration_ms BIGINT,
+  error TEXT NOT NULL DEFAULT '',
+  failure_class TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS job_attempts_job_idx
+  ON job_attempts (job_id, attempt_id);
+
+COMMENT ON TABLE job_attempts IS 'One row per lease of a job; attempt numbers restart when a DLQ replay resets attempt_count.';
+COMMENT ON COLUMN job_attempts.outcome IS 'running, succeeded, failed, terminal or lease_expired.';
@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS job_events (
+  event_id BIGSERIAL PRIMARY KEY,
+  job_id UUID NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+  from_state job_state,
+  to_state job_state NOT NULL,
+  actor TEXT NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS job_events_job_idx
+  ON job_events (job_id, event_id);
+
+COMMENT ON TABLE job_events IS 'Append-only log of job state changes, written by the jobs_record_event trigger.';
+COMMENT ON COLUMN job_events.actor IS 'api, scheduler, reaper or worker:<lease owner>.';
+
+-- Writers set taskforge.actor and taskforge.reason with set_config(..., true)
+-- in the transaction that changes the state. Leases do not, so their actor is
+-- derived from the new lease owner.
+CREATE OR REPLACE FUNCTION job_record_event()
+RETURNS TRIGGER AS $$
+DECLARE
+  v_actor TEXT := NULLIF(current_setting('taskforge.actor', true), '');
+  v_reason TEXT := COALESCE(current_setting('taskforge.reason', true), '');
+BEGIN
+  IF TG_OP = 'UPDATE' AND OLD.state = NEW.state THEN
+    RETURN NULL;
+  END IF;
+  IF v_actor IS NULL THEN
+    IF NEW.state = 'IN_PROGRESS' AND NEW.lease_owner IS NOT NULL THEN
+      v_actor := 'worker:' || NEW.lease_owner;
+      v_reason := 'leased';
+    ELSE
+      v_actor := 'system';
+    END IF;
+  END IF;
+
+  INSERT INTO job_events (job_id, from_state, to_state, actor, reason)
+  VALUES (
+    NEW.job_id,
+    CASE WHEN TG_OP = 'UPDATE' THEN OLD.state END,
+    NEW.state,
+    v_actor,
+    v_reason
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS jobs_record_event ON jobs;
+CREATE TRIGGER jobs_record_event
+AFTER INSERT OR UPDATE OF state ON jobs
+FOR EACH ROW
+EXECUTE FUNCTION job_record_event();
+
+CREATE OR REPLACE FUNCTION job_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'job_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS job_events_append_only ON job_events;
+CREATE TRIGGER job_events_append_only
+BEFORE UPDATE ON job_events
+FOR EACH ROW
+EXECUTE FUNCTION job_events_append_only();
@@ -0,0 +1,6 @@
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS result JSONB,
+  ADD COLUMN IF NOT EXISTS result_content_type TEXT;
+
+COMMENT ON COLUMN jobs.result IS 'JSON output stored by the handler of a COMPLETED job; capped at 64 KiB by the worker.';
+COMMENT ON COLUMN jobs.result_content_type IS 'Content type returned by GET /jobs/{id}/result; defaults to application/json.';
@@ -0,0 +1,39 @@
+-- Announce every job_events row on the taskforge:job_events channel so API
+-- waiters can follow state changes from one LISTEN connection instead of
+-- polling. pg_notify is delivered on commit, after the change is visible.
+CREATE OR REPLACE FUNCTION job_record_event()
+RETURNS TRIGGER AS $$
+DECLARE
+  v_actor TEXT := NULLIF(current_setting('taskforge.actor', true), '');
+  v_reason TEXT := COALESCE(current_setting('taskforge.reason', true), '');
+  v_event_id BIGINT;
+BEGIN
+  IF TG_OP = 'UPDATE' AND OLD.state = NEW.state THEN
+    RETURN NULL;
+  END IF;
+  IF v_actor IS NULL THEN
+    IF NEW.state = 'IN_PROGRESS' AND NEW.lease_owner IS NOT NULL THEN
+      v_actor := 'worker:' || NEW.lease_owner;
+      v_reason := 'leased';
+    ELSE
+      v_actor := 'system';
+    END IF;
+  END IF;
+
+  INSERT INTO job_events (job_id, from_state, to_state, actor, reason)
+  VALUES (
+    NEW.job_id,
+    CASE WHEN TG_OP = 'UPDATE' THEN OLD.state END,
+    NEW.state,
+    v_actor,
+    v_reason
+  )
+  RETURNING event_id INTO v_event_id;
+
+  PERFORM pg_notify(
+    'taskforge:job_events',
+    json_build_object('id', v_event_id, 'jobId', NEW.job_id, 'state', NEW.state)::text
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,48 @@
+-- Carry what GET /events filters on in the notification, so the API can route
+-- a change to its subscribers without reading the job. The reason is left
+-- out: it can hold a long error and pg_notify payloads are capped at 8000
+-- bytes.
+CREATE OR REPLACE FUNCTION job_record_event()
+RETURNS TRIGGER AS $$
+DECLARE
+  v_actor TEXT := NULLIF(current_setting('taskforge.actor', true), '');
+  v_reason TEXT := COALESCE(current_setting('taskforge.reason', true), '');
+  v_from job_state;
+  v_event_id BIGINT;
+  v_created_at TIMESTAMPTZ;
+BEGIN
+  IF TG_OP = 'UPDATE' AND OLD.state = NEW.state THEN
+    RETURN NULL;
+  END IF;
+  IF v_actor IS NULL THEN
+    IF NEW.state = 'IN_PROGRESS' AND NEW.lease_owner IS NOT NULL THEN
+      v_actor := 'worker:' || NEW.lease_owner;
+      v_reason := 'leased';
+    ELSE
+      v_actor := 'system';
+    END IF;
+  END IF;
+  IF TG_OP = 'UPDATE' THEN
+    v_from := OLD.state;
+  END IF;
+
+  INSERT INTO job_events (job_id, from_state, to_state, actor, reason)
+  VALUES (NEW.job_id, v_from, NEW.state, v_actor, v_reason)
+  RETURNING event_id, created_at INTO v_event_id, v_created_at;
+
+  PERFORM pg_notify(
+    'taskforge:job_events',
+    json_build_object(
+      'id', v_event_id,
+      'jobId', NEW.job_id,
+      'queue', NEW.queue_name,
+      'jobType', NEW.job_type,
+      'fromState', v_from,
+      'state', NEW.state,
+      'actor', v_actor,
+      'at', v_created_at
+    )::text
+  );
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,36 @@
+ALTER TYPE job_state ADD VALUE IF NOT EXISTS 'CANCELED';
+
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS cancel_requested_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS cancel_reason TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN jobs.cancel_requested_at IS 'Set by POST /jobs/{id}/cancel on a running job; the worker heartbeat sees it and cancels the handler.';
+COMMENT ON COLUMN jobs.cancel_reason IS 'Reason given with the cancel request, recorded on the CANCELED event.';
+COMMENT ON COLUMN job_attempts.outcome IS 'running, succeeded, failed, terminal, lease_expired or canceled.';
+
+CREATE OR REPLACE FUNCTION job_state_transition_ok(old_state job_state, new_state job_state)
+RETURNS BOOLEAN AS $$
+BEGIN
+  IF old_state = new_state THEN
+    RETURN TRUE;
+  END IF;
+
+  IF old_state = 'PENDING' AND new_state IN ('IN_PROGRESS', 'DLQ') THEN
+    RETURN TRUE;
+  END IF;
+  IF old_state = 'IN_PROGRESS' AND new_state IN ('COMPLETED', 'FAILED', 'RETRYING', 'DLQ', 'PENDING', 'CANCELED') THEN
+    RETURN TRUE;
+  END IF;
+  IF old_state = 'FAILED' AND new_state IN ('RETRYING', 'DLQ', 'PENDING') THEN
+    RETURN TRUE;
+  END IF;
+  IF old_state = 'RETRYING' AND new_state IN ('PENDING', 'DLQ') THEN
+    RETURN TRUE;
+  END IF;
+  IF old_state = 'DLQ' AND new_state IN ('PENDING') THEN
+    RETURN TRUE;
+  END IF;
+
+  RETURN FALSE;
+END;
+$$ LANGUAGE plpgsql;
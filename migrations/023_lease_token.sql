@@ -0,0 +1,8 @@
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS lease_token BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE job_attempts
+  ADD COLUMN IF NOT EXISTS lease_token BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN jobs.lease_token IS 'Fencing token, incremented by every lease of the job and never reset. Renewals and outcome writes must present the current value.';
+COMMENT ON COLUMN job_attempts.lease_token IS 'Lease token the attempt ran under.';
@@ -0,0 +1,4 @@
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS timeout_ms INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN jobs.timeout_ms IS 'Upper bound on one attempt, enforced by the worker. 0 uses the worker default for the job type.';
@@ -0,0 +1,4 @@
+ALTER TABLE jobs
+  ADD COLUMN IF NOT EXISTS lease_expirations INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN jobs.lease_expirations IS 'Times a lease on the job expired without an outcome, usually because the job crashed its worker. Reset by a DLQ replay.';